@@ -0,0 +1,35 @@
+terraform {
+  required_providers {
+    linux = {
+      source = "beleap/linux"
+    }
+  }
+}
+
+provider "linux" {
+  host        = "test-node.fox-deneb.ts.net"
+  username    = "root"
+  private_key = file("../../ssh-keys/id_rsa")
+}
+
+resource "linux_user" "changseo_jang" {
+  username    = "testuser-changseo-jang"
+  create_home = true
+}
+
+resource "linux_ssh_authorized_keys" "changseo_jang" {
+  username  = linux_user.changseo_jang.username
+  exclusive = true
+
+  keys = [
+    {
+      key = file("../../ssh-keys/id_rsa.pub")
+    },
+    {
+      key     = file("../../ssh-keys/deploy.pub")
+      from    = "10.0.0.0/8"
+      command = "/usr/local/bin/deploy"
+      no_pty  = true
+    },
+  ]
+}
@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    linux = {
+      source = "beleap/linux"
+    }
+  }
+}
+
+provider "linux" {
+  host        = "10.0.1.15"
+  username    = "root"
+  private_key = file("../../ssh-keys/id_rsa")
+
+  bastion {
+    host     = "bastion.example.com"
+    username = "jump"
+    agent    = true
+  }
+}
+
+data "linux_user" "root" {
+  username = "root"
+}
+
+output "root" {
+  value = data.linux_user.root
+}
@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    linux = {
+      source = "beleap/linux"
+    }
+  }
+}
+
+provider "linux" {
+  host        = "10.0.1.15"
+  username    = "deploy"
+  private_key = file("../../ssh-keys/id_rsa")
+
+  become {
+    method          = "sudo"
+    become_password = var.sudo_password
+  }
+}
+
+variable "sudo_password" {
+  type      = string
+  sensitive = true
+}
+
+resource "linux_user" "app" {
+  username = "app"
+}
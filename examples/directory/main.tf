@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    linux = {
+      source = "beleap/linux"
+    }
+  }
+}
+
+provider "linux" {
+  host        = "test-node.fox-deneb.ts.net"
+  username    = "root"
+  private_key = file("../../ssh-keys/id_rsa")
+}
+
+resource "linux_directory" "app" {
+  path      = "/opt/app"
+  mode      = "0750"
+  owner     = "root"
+  group     = "root"
+  recursive = true
+  purge     = true
+  keep      = ["app.conf"]
+}
+
+resource "linux_file" "app_conf" {
+  path    = "${linux_directory.app.path}/app.conf"
+  content = "listen = 8080\n"
+}
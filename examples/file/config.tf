@@ -20,3 +20,27 @@ data "linux_file" "test" {
 output "test" {
   value = data.linux_file.test
 }
+
+resource "linux_file" "motd" {
+  path    = "/etc/motd"
+  content = "Managed by terraform\n"
+  mode    = "0644"
+  owner   = "root"
+  group   = "root"
+}
+
+resource "linux_file_acl" "shared" {
+  path  = "/srv/shared"
+  user  = 7
+  group = 5
+  other = 0
+  users = [
+    { id = 1000, permission = 7 },
+  ]
+
+  default = {
+    user  = 7
+    group = 5
+    other = 0
+  }
+}
@@ -0,0 +1,41 @@
+terraform {
+  required_providers {
+    linux = {
+      source = "beleap/linux"
+    }
+  }
+}
+
+provider "linux" {
+  host        = "test-node.fox-deneb.ts.net"
+  username    = "root"
+  private_key = file("../../ssh-keys/id_rsa")
+}
+
+resource "linux_user" "changseo_jang" {
+  username = "testuser-changseo-jang"
+}
+
+resource "linux_group" "developers" {
+  name    = "developers"
+  gid     = 3000
+  members = [linux_user.changseo_jang.username]
+}
+
+resource "linux_group" "docker" {
+  name = "docker"
+}
+
+# Other members of the group, added elsewhere, are left alone.
+resource "linux_group_membership" "docker" {
+  group = linux_group.docker.name
+  users = [linux_user.changseo_jang.username]
+}
+
+data "linux_group" "developers" {
+  name = linux_group.developers.name
+}
+
+output "developers" {
+  value = data.linux_group.developers
+}
@@ -0,0 +1,16 @@
+terraform {
+  required_providers {
+    linux = {
+      source = "beleap/linux"
+    }
+  }
+}
+
+provider "linux" {
+  connection = "local"
+}
+
+resource "linux_directory" "app" {
+  path = "/opt/app"
+  mode = "0755"
+}
@@ -17,10 +17,23 @@ data "linux_user" "root" {
 }
 
 resource "linux_user" "changseo_jang" {
-  username = "testuser-changseo-jang"
-  gid      = 2000
+  username    = "testuser-changseo-jang"
+  gid         = 2000
+  comment     = "Changseo Jang"
+  shell       = "/bin/bash"
+  groups      = ["sudo"]
+  create_home = true
+}
+
+data "linux_users" "human" {
+  system = false
+  shells = ["/bin/bash"]
 }
 
 output "root" {
   value = data.linux_user.root
 }
+
+output "human_users" {
+  value = { for user in data.linux_users.human.users : user.username => user.uid }
+}
@@ -0,0 +1,436 @@
+package authorizedkeys
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"terraform-provider-linux/internal/file"
+	"terraform-provider-linux/internal/user"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// AuthorizedKey is an entry of authorized_keys. Key is the public key in authorized_keys format, with its comment.
+type AuthorizedKey struct {
+	Key     string
+	From    string
+	Command string
+	NoPty   bool
+}
+
+// LinuxAuthorizedKeys is the authorized_keys file of a user. Lines are nil when the file does not exist.
+// Self is true when commands already run as the user, such as a login user managing its own keys without become.
+type LinuxAuthorizedKeys struct {
+	Path   string
+	Uid    int64
+	Gid    int64
+	Lines  []string
+	Secure bool
+	Self   bool
+}
+
+type AuthorizedKeyModel struct {
+	Key     types.String `tfsdk:"key"`
+	From    types.String `tfsdk:"from"`
+	Command types.String `tfsdk:"command"`
+	NoPty   types.Bool   `tfsdk:"no_pty"`
+}
+
+type LinuxAuthorizedKeysResourceModel struct {
+	Username  types.String         `tfsdk:"username"`
+	Path      types.String         `tfsdk:"path"`
+	Exclusive types.Bool           `tfsdk:"exclusive"`
+	Keys      []AuthorizedKeyModel `tfsdk:"keys"`
+}
+
+func newAuthorizedKeyModel(key *AuthorizedKey) AuthorizedKeyModel {
+	model := AuthorizedKeyModel{
+		Key:     types.StringValue(key.Key),
+		From:    types.StringNull(),
+		Command: types.StringNull(),
+		NoPty:   types.BoolValue(key.NoPty),
+	}
+	if key.From != "" {
+		model.From = types.StringValue(key.From)
+	}
+	if key.Command != "" {
+		model.Command = types.StringValue(key.Command)
+	}
+	return model
+}
+
+func (m *AuthorizedKeyModel) toAuthorizedKey() *AuthorizedKey {
+	return &AuthorizedKey{
+		Key:     m.Key.ValueString(),
+		From:    m.From.ValueString(),
+		Command: m.Command.ValueString(),
+		NoPty:   m.NoPty.ValueBool(),
+	}
+}
+
+// authorizedKeys returns the keys of the model, in order.
+func (m *LinuxAuthorizedKeysResourceModel) authorizedKeys() []*AuthorizedKey {
+	keys := []*AuthorizedKey{}
+	for _, key := range m.Keys {
+		keys = append(keys, key.toAuthorizedKey())
+	}
+	return keys
+}
+
+// applyLinuxAuthorizedKeys refreshes the model from the file. Declared keys missing from the file are dropped
+// and exclusive is reported as disabled when unmanaged keys are found, so that the next plan converges them.
+// Without declared keys, as after import, every key of the file is taken.
+func (m *LinuxAuthorizedKeysResourceModel) applyLinuxAuthorizedKeys(authorizedKeys *LinuxAuthorizedKeys) {
+	m.Path = types.StringValue(authorizedKeys.Path)
+	if m.Exclusive.IsNull() {
+		m.Exclusive = types.BoolValue(false)
+	}
+
+	found := map[string]*AuthorizedKey{}
+	order := []string{}
+	for _, line := range authorizedKeys.Lines {
+		key, fingerprint := parseAuthorizedKey(line)
+		if key == nil {
+			continue
+		}
+		found[fingerprint] = key
+		order = append(order, fingerprint)
+	}
+
+	// Keys in a file with wrong permissions are ignored by sshd, so they are not reported as present.
+	if !authorizedKeys.Secure {
+		found = map[string]*AuthorizedKey{}
+	}
+
+	keys := []AuthorizedKeyModel{}
+	managed := map[string]bool{}
+	if m.Keys == nil {
+		for _, fingerprint := range order {
+			if !managed[fingerprint] {
+				keys = append(keys, newAuthorizedKeyModel(found[fingerprint]))
+				managed[fingerprint] = true
+			}
+		}
+	} else {
+		for _, keyModel := range m.Keys {
+			fingerprint, err := keyFingerprint(keyModel.Key.ValueString())
+			if err != nil {
+				continue
+			}
+			key, ok := found[fingerprint]
+			if !ok {
+				continue
+			}
+			// The declared key is kept, since its comment is not significant.
+			key.Key = keyModel.Key.ValueString()
+			keys = append(keys, newAuthorizedKeyModel(key))
+			managed[fingerprint] = true
+		}
+	}
+	m.Keys = keys
+
+	for fingerprint := range found {
+		if !managed[fingerprint] {
+			m.Exclusive = types.BoolValue(false)
+		}
+	}
+}
+
+// keyFingerprint returns the type and base64 blob of a public key in authorized_keys format, which identify the key.
+func keyFingerprint(key string) (string, error) {
+	publicKey, _, options, rest, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return "", err
+	}
+	if len(options) != 0 {
+		return "", errors.New("options are not allowed in the key, use from, command and no_pty instead")
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return "", errors.New("only a single key is allowed")
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))), nil
+}
+
+// parseAuthorizedKey parses a line of authorized_keys, returning nil for comments, blank and invalid lines.
+// Options other than from, command and no-pty are ignored.
+func parseAuthorizedKey(line string) (*AuthorizedKey, string) {
+	publicKey, comment, options, _, err := ssh.ParseAuthorizedKey([]byte(line))
+	if err != nil {
+		return nil, ""
+	}
+
+	fingerprint := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+	key := &AuthorizedKey{
+		Key: fingerprint,
+	}
+	if comment != "" {
+		key.Key = fingerprint + " " + comment
+	}
+	for _, option := range options {
+		name, value, _ := strings.Cut(option, "=")
+		switch strings.ToLower(name) {
+		case "from":
+			key.From = unquoteOption(value)
+		case "command":
+			key.Command = unquoteOption(value)
+		case "no-pty":
+			key.NoPty = true
+		}
+	}
+	return key, fingerprint
+}
+
+func unquoteOption(value string) string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "\""), "\"")
+	return strings.ReplaceAll(value, "\\\"", "\"")
+}
+
+func quoteOption(value string) string {
+	return "\"" + strings.ReplaceAll(value, "\"", "\\\"") + "\""
+}
+
+// formatAuthorizedKey returns the authorized_keys line of key. Surrounding whitespace of the key is dropped,
+// such as the trailing newline of a .pub file.
+func formatAuthorizedKey(key *AuthorizedKey) string {
+	publicKey := strings.TrimSpace(key.Key)
+	options := []string{}
+	if key.From != "" {
+		options = append(options, "from="+quoteOption(key.From))
+	}
+	if key.Command != "" {
+		options = append(options, "command="+quoteOption(key.Command))
+	}
+	if key.NoPty {
+		options = append(options, "no-pty")
+	}
+
+	if len(options) == 0 {
+		return publicKey
+	}
+	return strings.Join(options, ",") + " " + publicKey
+}
+
+// mergeAuthorizedKeys returns the lines of authorized_keys with the declared keys. Declared keys replace
+// existing lines of the same key and are appended otherwise. Keys listed in removed are dropped, and so is
+// every other key when exclusive is set. Comments and blank lines are kept.
+func mergeAuthorizedKeys(lines []string, keys []*AuthorizedKey, removed []string, exclusive bool) ([]string, error) {
+	declared := map[string]*AuthorizedKey{}
+	order := []string{}
+	for _, key := range keys {
+		fingerprint, err := keyFingerprint(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key \"%s\": %v", key.Key, err)
+		}
+		if _, ok := declared[fingerprint]; !ok {
+			order = append(order, fingerprint)
+		}
+		declared[fingerprint] = key
+	}
+	dropped := map[string]bool{}
+	for _, key := range removed {
+		if fingerprint, err := keyFingerprint(key); err == nil {
+			dropped[fingerprint] = true
+		}
+	}
+
+	result := []string{}
+	written := map[string]bool{}
+	for _, line := range lines {
+		existing, fingerprint := parseAuthorizedKey(line)
+		if existing == nil {
+			result = append(result, line)
+			continue
+		}
+		if key, ok := declared[fingerprint]; ok {
+			if !written[fingerprint] {
+				result = append(result, formatAuthorizedKey(key))
+				written[fingerprint] = true
+			}
+			continue
+		}
+		if exclusive || dropped[fingerprint] {
+			continue
+		}
+		result = append(result, line)
+	}
+	for _, fingerprint := range order {
+		if !written[fingerprint] {
+			result = append(result, formatAuthorizedKey(declared[fingerprint]))
+		}
+	}
+	return result, nil
+}
+
+// hasAuthorizedKeys reports whether lines contain any key.
+func hasAuthorizedKeys(lines []string) bool {
+	for _, line := range lines {
+		if key, _ := parseAuthorizedKey(line); key != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(content, "\n")
+}
+
+// secure reports whether stat is owned by the user with the given mode.
+func secure(stat *file.LinuxFile, linuxUser *user.LinuxUser, mode string) bool {
+	return stat != nil && stat.Uid == linuxUser.Uid && stat.Gid == linuxUser.Gid && file.ModeEqual(stat.Mode, mode)
+}
+
+// asUser returns argv run with the uid and gid of the owner of authorizedKeys. The user controls its home
+// directory, so links placed there must never be followed with the privileges of the provider.
+// setpriv needs root, so it is skipped when commands already run as the user.
+func asUser(authorizedKeys *LinuxAuthorizedKeys, argv ...string) string {
+	if authorizedKeys.Self {
+		return sshUtil.Command(argv...)
+	}
+	setpriv := []string{"setpriv", "--reuid", strconv.FormatInt(authorizedKeys.Uid, 10), "--regid", strconv.FormatInt(authorizedKeys.Gid, 10), "--clear-groups", "--"}
+	return sshUtil.Command(append(setpriv, argv...)...)
+}
+
+// effectiveUid returns the uid commands run with, which is the one of the become user when configured.
+func effectiveUid(linuxCtx util.LinuxContext) (int64, *util.CommonError) {
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("id", "-u"), nil)
+	if commonError != nil {
+		return 0, commonError
+	}
+	uid, err := strconv.ParseInt(strings.TrimSpace(result.Stdout), 10, 64)
+	if err != nil {
+		return 0, &util.CommonError{
+			Error: err,
+			Diagnostics: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed to parse id", fmt.Sprintf("Error: %v\nFailed to parse id content:\n%s", err, result.Stdout)),
+			},
+		}
+	}
+	return uid, nil
+}
+
+// checkType fails unless stat, which does not follow links, is of the expected type.
+func checkType(stat *file.LinuxFile, expected string) *util.CommonError {
+	if stat.Type == expected {
+		return nil
+	}
+	diagnostic := diag.NewErrorDiagnostic(
+		"Unexpected file type",
+		fmt.Sprintf("Refusing to use \"%s\", which is a %s instead of a %s", stat.Path, stat.Type, expected),
+	)
+	return &util.CommonError{
+		Diagnostics: diag.Diagnostics{diagnostic},
+	}
+}
+
+// Get returns the authorized_keys file of username, or nil if the user does not exist.
+// ~/.ssh must be a directory and authorized_keys a regular file, links are refused.
+func Get(linuxCtx util.LinuxContext, username string) (*LinuxAuthorizedKeys, *util.CommonError) {
+	linuxUser, commonError := user.Get(linuxCtx, username)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if linuxUser == nil {
+		return nil, nil
+	}
+
+	uid, commonError := effectiveUid(linuxCtx)
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	directoryPath := path.Join(linuxUser.Home, ".ssh")
+	authorizedKeys := &LinuxAuthorizedKeys{
+		Path: path.Join(directoryPath, "authorized_keys"),
+		Uid:  linuxUser.Uid,
+		Gid:  linuxUser.Gid,
+		Self: uid == linuxUser.Uid,
+	}
+
+	directoryStat, commonError := file.Stat(linuxCtx, directoryPath)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if directoryStat == nil {
+		return authorizedKeys, nil
+	}
+	if commonError = checkType(directoryStat, "directory"); commonError != nil {
+		return nil, commonError
+	}
+
+	stat, commonError := file.Stat(linuxCtx, authorizedKeys.Path)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if stat == nil {
+		return authorizedKeys, nil
+	}
+	if commonError = checkType(stat, "file"); commonError != nil {
+		return nil, commonError
+	}
+	authorizedKeys.Secure = secure(directoryStat, linuxUser, "0700") && secure(stat, linuxUser, "0600")
+
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, asUser(authorizedKeys, "cat", "--", authorizedKeys.Path), nil)
+	if commonError != nil {
+		return nil, commonError
+	}
+	authorizedKeys.Lines = splitLines(result.Stdout)
+
+	return authorizedKeys, nil
+}
+
+// Write replaces the authorized_keys file of the user with lines, creating ~/.ssh if needed.
+// Both are owned by the user, with 0700 permissions on the directory and 0600 on the file.
+// A ~/.ssh owned by another user is handed over first, and everything else runs as the user:
+// the file is written to a temporary file of ~/.ssh and renamed over authorized_keys.
+func Write(linuxCtx util.LinuxContext, authorizedKeys *LinuxAuthorizedKeys, lines []string) *util.CommonError {
+	directoryPath := path.Dir(authorizedKeys.Path)
+
+	directoryStat, commonError := file.Stat(linuxCtx, directoryPath)
+	if commonError != nil {
+		return commonError
+	}
+	if directoryStat != nil {
+		if commonError = checkType(directoryStat, "directory"); commonError != nil {
+			return commonError
+		}
+		if directoryStat.Uid != authorizedKeys.Uid || directoryStat.Gid != authorizedKeys.Gid {
+			ownership := strconv.FormatInt(authorizedKeys.Uid, 10) + ":" + strconv.FormatInt(authorizedKeys.Gid, 10)
+			_, _, commonError = sshUtil.RunCommand(linuxCtx, sshUtil.Command("chown", "-h", "--", ownership, directoryPath), nil)
+			if commonError != nil {
+				return commonError
+			}
+		}
+	}
+
+	content := ""
+	if len(lines) != 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+	script := strings.Join([]string{
+		"umask 077",
+		sshUtil.Command("mkdir", "-p", "--", directoryPath),
+		sshUtil.Command("chmod", "0700", "--", directoryPath),
+		"tmp=$(" + sshUtil.Command("mktemp", "--", path.Join(directoryPath, ".authorized_keys.XXXXXX")) + ")",
+		"{ cat > \"$tmp\" && " + sshUtil.Command("mv", "-f", "--") + " \"$tmp\" " + sshUtil.Command(authorizedKeys.Path) + " || { rm -f -- \"$tmp\"; exit 1; }; }",
+	}, " && ")
+	_, _, commonError = sshUtil.RunCommandWithStdin(linuxCtx, asUser(authorizedKeys, "sh", "-c", script), content, nil)
+	return commonError
+}
+
+// Remove deletes the authorized_keys file as the user, keeping ~/.ssh since it may hold other files.
+func Remove(linuxCtx util.LinuxContext, authorizedKeys *LinuxAuthorizedKeys) *util.CommonError {
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, asUser(authorizedKeys, "rm", "-f", "--", authorizedKeys.Path), nil)
+	return commonError
+}
@@ -0,0 +1,184 @@
+package authorizedkeys
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"terraform-provider-linux/internal/util/sshtest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// testKey returns a public key in authorized_keys format, derived from seed.
+func testKey(t *testing.T, seed byte) string {
+	privateKey := ed25519.NewKeyFromSeed([]byte(strings.Repeat(string([]byte{seed}), ed25519.SeedSize)))
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	assert.NilError(t, err)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+}
+
+func TestParseAuthorizedKey(t *testing.T) {
+	key := testKey(t, 1)
+
+	parsed, fingerprint := parseAuthorizedKey(`from="10.0.0.0/8",command="echo \"hi\"",no-pty,restrict ` + key + " alice@laptop")
+	assert.DeepEqual(t, &AuthorizedKey{Key: key + " alice@laptop", From: "10.0.0.0/8", Command: `echo "hi"`, NoPty: true}, parsed)
+	assert.Equal(t, key, fingerprint)
+
+	parsed, _ = parseAuthorizedKey("# " + key)
+	assert.Assert(t, is.Nil(parsed))
+}
+
+func TestFormatAuthorizedKey(t *testing.T) {
+	key := testKey(t, 1)
+
+	assert.Equal(t, key, formatAuthorizedKey(&AuthorizedKey{Key: key + "\n"}))
+	line := formatAuthorizedKey(&AuthorizedKey{Key: key + " alice", From: "10.0.0.0/8", Command: `echo "hi"`, NoPty: true})
+	assert.Equal(t, `from="10.0.0.0/8",command="echo \"hi\"",no-pty `+key+" alice", line)
+
+	parsed, _ := parseAuthorizedKey(line)
+	assert.Equal(t, `echo "hi"`, parsed.Command)
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	key := testKey(t, 1)
+
+	fingerprint, err := keyFingerprint(key + " alice")
+	assert.NilError(t, err)
+	assert.Equal(t, key, fingerprint)
+
+	_, err = keyFingerprint("no-pty " + key)
+	assert.ErrorContains(t, err, "options are not allowed")
+	_, err = keyFingerprint("ssh-ed25519 invalid")
+	assert.Assert(t, err != nil)
+}
+
+func TestMergeAuthorizedKeys(t *testing.T) {
+	alice, bob, carol := testKey(t, 1), testKey(t, 2), testKey(t, 3)
+	lines := []string{"# managed by hand", alice + " alice", bob + " bob"}
+
+	// Declared keys replace their line, and other keys are kept unless removed or exclusive.
+	merged, err := mergeAuthorizedKeys(lines, []*AuthorizedKey{{Key: alice + " alice", NoPty: true}, {Key: carol}}, nil, false)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"# managed by hand", "no-pty " + alice + " alice", bob + " bob", carol}, merged)
+
+	merged, err = mergeAuthorizedKeys(lines, []*AuthorizedKey{{Key: carol}}, []string{alice}, false)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"# managed by hand", bob + " bob", carol}, merged)
+
+	merged, err = mergeAuthorizedKeys(lines, []*AuthorizedKey{{Key: carol}}, nil, true)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"# managed by hand", carol}, merged)
+	assert.Assert(t, !hasAuthorizedKeys([]string{"# managed by hand"}))
+}
+
+func TestApplyLinuxAuthorizedKeys(t *testing.T) {
+	alice, bob := testKey(t, 1), testKey(t, 2)
+	authorizedKeys := &LinuxAuthorizedKeys{
+		Path:   "/home/alice/.ssh/authorized_keys",
+		Lines:  []string{`from="10.0.0.0/8" ` + alice + " laptop", bob},
+		Secure: true,
+	}
+
+	// Unmanaged keys disable exclusive, and declared keys take their options from the file.
+	model := LinuxAuthorizedKeysResourceModel{
+		Exclusive: types.BoolValue(true),
+		Keys:      []AuthorizedKeyModel{newAuthorizedKeyModel(&AuthorizedKey{Key: alice + " alice"})},
+	}
+	model.applyLinuxAuthorizedKeys(authorizedKeys)
+	assert.Equal(t, "/home/alice/.ssh/authorized_keys", model.Path.ValueString())
+	assert.Assert(t, !model.Exclusive.ValueBool())
+	assert.DeepEqual(t, []AuthorizedKeyModel{newAuthorizedKeyModel(&AuthorizedKey{Key: alice + " alice", From: "10.0.0.0/8"})}, model.Keys)
+
+	// Imported resources take every key.
+	model = LinuxAuthorizedKeysResourceModel{}
+	model.applyLinuxAuthorizedKeys(authorizedKeys)
+	assert.Equal(t, 2, len(model.Keys))
+
+	// Keys of an insecure file are not reported.
+	authorizedKeys.Secure = false
+	model.applyLinuxAuthorizedKeys(authorizedKeys)
+	assert.Equal(t, 0, len(model.Keys))
+}
+
+func TestGet(t *testing.T) {
+	server := sshtest.NewServer(t).
+		Handle("getent passwd alice", sshtest.Response{Stdout: "alice:x:1000:1000::/home/alice:/bin/sh\n"}).
+		Handle("id -Gn -- alice", sshtest.Response{Stdout: "alice\n"}).
+		Handle("id -u", sshtest.Response{Stdout: "0\n"}).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh", sshtest.Response{Stdout: "directory:700:1000:alice:1000:alice\n"}).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh/authorized_keys", sshtest.Response{ExitCode: 1, Stderr: "stat: cannot statx '/home/alice/.ssh/authorized_keys': No such file or directory\n"})
+
+	authorizedKeys, err := Get(server.LinuxContext(t), "alice")
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, &LinuxAuthorizedKeys{Path: "/home/alice/.ssh/authorized_keys", Uid: 1000, Gid: 1000}, authorizedKeys)
+
+	// The file is read with the ids of the user
+	server.
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh/authorized_keys", sshtest.Response{Stdout: "regular file:600:1000:alice:1000:alice\n"}).
+		Handle("setpriv --reuid 1000 --regid 1000 --clear-groups -- cat -- /home/alice/.ssh/authorized_keys", sshtest.Response{Stdout: testKey(t, 1) + "\n"})
+
+	authorizedKeys, err = Get(server.LinuxContext(t), "alice")
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, []string{testKey(t, 1)}, authorizedKeys.Lines)
+	assert.Assert(t, authorizedKeys.Secure)
+
+	server.Handle("getent passwd user_not_exists", sshtest.Response{ExitCode: 2})
+	authorizedKeys, err = Get(server.LinuxContext(t), "user_not_exists")
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, is.Nil(authorizedKeys))
+}
+
+func TestGetRefusesLinks(t *testing.T) {
+	server := sshtest.NewServer(t).
+		Handle("getent passwd alice", sshtest.Response{Stdout: "alice:x:1000:1000::/home/alice:/bin/sh\n"}).
+		Handle("id -Gn -- alice", sshtest.Response{Stdout: "alice\n"}).
+		Handle("id -u", sshtest.Response{Stdout: "0\n"}).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh", sshtest.Response{Stdout: "directory:700:1000:alice:1000:alice\n"}).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh/authorized_keys", sshtest.Response{Stdout: "symbolic link:777:1000:alice:1000:alice\n"})
+
+	_, err := Get(server.LinuxContext(t), "alice")
+	assert.Assert(t, err != nil)
+	assert.Equal(t, "Refusing to use \"/home/alice/.ssh/authorized_keys\", which is a symbolic link instead of a file", err.Diagnostics[0].Detail())
+
+	server.Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh", sshtest.Response{Stdout: "symbolic link:777:1000:alice:1000:alice\n"})
+
+	_, err = Get(server.LinuxContext(t), "alice")
+	assert.Assert(t, err != nil)
+	assert.Equal(t, "Refusing to use \"/home/alice/.ssh\", which is a symbolic link instead of a directory", err.Diagnostics[0].Detail())
+
+	// Nothing was read
+	for _, command := range server.Commands() {
+		assert.Assert(t, !strings.Contains(command, "cat"), command)
+	}
+}
+
+func TestGetAsSelf(t *testing.T) {
+	// A login user managing its own keys without become, which cannot run setpriv
+	server := sshtest.NewServer(t).
+		Handle("getent passwd alice", sshtest.Response{Stdout: "alice:x:1000:1000::/home/alice:/bin/sh\n"}).
+		Handle("id -Gn -- alice", sshtest.Response{Stdout: "alice\n"}).
+		Handle("id -u", sshtest.Response{Stdout: "1000\n"}).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh", sshtest.Response{Stdout: "directory:700:1000:alice:1000:alice\n"}).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /home/alice/.ssh/authorized_keys", sshtest.Response{Stdout: "regular file:600:1000:alice:1000:alice\n"}).
+		Handle("cat -- /home/alice/.ssh/authorized_keys", sshtest.Response{Stdout: testKey(t, 1) + "\n"}).
+		HandleFunc(func(command string, stdin string) (sshtest.Response, bool) {
+			return sshtest.Response{}, strings.HasPrefix(command, "sh -c ") || command == "rm -f -- /home/alice/.ssh/authorized_keys"
+		})
+
+	authorizedKeys, err := Get(server.LinuxContext(t), "alice")
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, authorizedKeys.Self)
+	assert.DeepEqual(t, []string{testKey(t, 1)}, authorizedKeys.Lines)
+
+	err = Write(server.LinuxContext(t), authorizedKeys, []string{testKey(t, 2)})
+	assert.Assert(t, is.Nil(err))
+	err = Remove(server.LinuxContext(t), authorizedKeys)
+	assert.Assert(t, is.Nil(err))
+
+	for _, command := range server.Commands() {
+		assert.Assert(t, !strings.Contains(command, "setpriv"), command)
+	}
+}
@@ -0,0 +1,313 @@
+package authorizedkeys
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+)
+
+var (
+	_ resource.Resource                   = &authorizedKeysResource{}
+	_ resource.ResourceWithConfigure      = &authorizedKeysResource{}
+	_ resource.ResourceWithImportState    = &authorizedKeysResource{}
+	_ resource.ResourceWithValidateConfig = &authorizedKeysResource{}
+)
+
+func NewAuthorizedKeysResource() resource.Resource {
+	return &authorizedKeysResource{}
+}
+
+type authorizedKeysResource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (r *authorizedKeysResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ssh_authorized_keys"
+}
+
+func (r *authorizedKeysResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the keys of `~/.ssh/authorized_keys` of a user. The directory and the file are owned by the user, with 0700 and 0600 permissions. Links in their place are refused, and the file is read and written with the ids of the user through `setpriv` of util-linux, unless the provider already runs as the user",
+		Attributes: map[string]schema.Attribute{
+			"username": schema.StringAttribute{
+				Description: "Name of the user",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"path": schema.StringAttribute{
+				Description: "Path of the authorized_keys file, below the home directory of the user",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"exclusive": schema.BoolAttribute{
+				Description: "Remove every key that is not declared. Otherwise keys added elsewhere are left alone",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"keys": schema.ListNestedAttribute{
+				Description: "Keys the user can log in with",
+				Required:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							Description: "Public key in authorized_keys format, such as `ssh-ed25519 AAAA... comment`",
+							Required:    true,
+						},
+						"from": schema.StringAttribute{
+							Description: "Patterns of hosts the key is accepted from, such as `10.0.0.0/8,*.example.com`",
+							Optional:    true,
+						},
+						"command": schema.StringAttribute{
+							Description: "Command that is run whenever the key is used, instead of the one requested",
+							Optional:    true,
+						},
+						"no_pty": schema.BoolAttribute{
+							Description: "Deny terminal allocation for the key",
+							Optional:    true,
+							Computed:    true,
+							Default:     booldefault.StaticBool(false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *authorizedKeysResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config LinuxAuthorizedKeysResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	fingerprints := map[string]bool{}
+	for i, key := range config.Keys {
+		if !key.Key.IsNull() && !key.Key.IsUnknown() {
+			fingerprint, err := keyFingerprint(key.Key.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("keys").AtListIndex(i).AtName("key"),
+					"Invalid key",
+					fmt.Sprintf("Key should be a single public key in authorized_keys format: %v", err),
+				)
+			} else if fingerprints[fingerprint] {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("keys").AtListIndex(i).AtName("key"),
+					"Duplicate key",
+					"Each key should be declared only once",
+				)
+			}
+			fingerprints[fingerprint] = true
+		}
+
+		for name, value := range map[string]string{"from": key.From.ValueString(), "command": key.Command.ValueString()} {
+			if strings.ContainsAny(value, "\n\r") {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("keys").AtListIndex(i).AtName(name),
+					"Invalid option",
+					"Option should not contain line breaks",
+				)
+			}
+		}
+	}
+}
+
+// apply writes the keys of the plan, dropping the removed ones, and returns the resulting file.
+func (r *authorizedKeysResource) apply(linuxCtx util.LinuxContext, plan *LinuxAuthorizedKeysResourceModel, removed []string) (*LinuxAuthorizedKeys, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+	username := plan.Username.ValueString()
+
+	authorizedKeys, commonError := Get(linuxCtx, username)
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+	if authorizedKeys == nil {
+		diags.AddAttributeError(path.Root("username"), "User not found", "User \""+username+"\" does not exist on the server")
+		return nil, diags
+	}
+
+	lines, err := mergeAuthorizedKeys(authorizedKeys.Lines, plan.authorizedKeys(), removed, plan.Exclusive.ValueBool())
+	if err != nil {
+		diags.AddError("Invalid keys", err.Error())
+		return nil, diags
+	}
+
+	commonError = Write(linuxCtx, authorizedKeys, lines)
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+
+	authorizedKeys, commonError = Get(linuxCtx, username)
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+	if authorizedKeys == nil {
+		diags.AddAttributeError(path.Root("username"), "User not found", "User \""+username+"\" was removed while writing its keys")
+		return nil, diags
+	}
+	return authorizedKeys, diags
+}
+
+func (r *authorizedKeysResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan LinuxAuthorizedKeysResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	authorizedKeys, diags := r.apply(linuxCtx, &plan, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.applyLinuxAuthorizedKeys(authorizedKeys)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *authorizedKeysResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxAuthorizedKeysResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authorizedKeys, commonError := Get(linuxCtx, state.Username.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if authorizedKeys == nil {
+		resp.State.RemoveResource(linuxCtx.Ctx)
+		return
+	}
+
+	state.applyLinuxAuthorizedKeys(authorizedKeys)
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *authorizedKeysResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var plan LinuxAuthorizedKeysResourceModel
+	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state LinuxAuthorizedKeysResourceModel
+	diags = req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keys that are no longer declared were managed by this resource, so they are removed even without exclusive.
+	removed := []string{}
+	for _, key := range state.Keys {
+		removed = append(removed, key.Key.ValueString())
+	}
+
+	authorizedKeys, diags := r.apply(linuxCtx, &plan, removed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.applyLinuxAuthorizedKeys(authorizedKeys)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *authorizedKeysResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxAuthorizedKeysResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authorizedKeys, commonError := Get(linuxCtx, state.Username.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if authorizedKeys == nil || authorizedKeys.Lines == nil {
+		return
+	}
+
+	removed := []string{}
+	for _, key := range state.Keys {
+		removed = append(removed, key.Key.ValueString())
+	}
+	lines, err := mergeAuthorizedKeys(authorizedKeys.Lines, nil, removed, false)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid keys", err.Error())
+		return
+	}
+
+	// The file is removed once no key is left.
+	if !hasAuthorizedKeys(lines) {
+		commonError = Remove(linuxCtx, authorizedKeys)
+	} else {
+		commonError = Write(linuxCtx, authorizedKeys, lines)
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+}
+
+func (r *authorizedKeysResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *authorizedKeysResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("username"), req, resp)
+}
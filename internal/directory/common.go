@@ -0,0 +1,139 @@
+package directory
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"terraform-provider-linux/internal/file"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type LinuxDirectory struct {
+	Path     string
+	Mode     string
+	Uid      int64
+	Owner    string
+	Gid      int64
+	Group    string
+	Children []string
+}
+
+type LinuxDirectoryModel struct {
+	Path      types.String `tfsdk:"path"`
+	Mode      types.String `tfsdk:"mode"`
+	Owner     types.String `tfsdk:"owner"`
+	Group     types.String `tfsdk:"group"`
+	Recursive types.Bool   `tfsdk:"recursive"`
+	Purge     types.Bool   `tfsdk:"purge"`
+	Keep      types.Set    `tfsdk:"keep"`
+}
+
+// applyLinuxDirectory updates the model with the state of the directory on the server.
+// Configured values are kept when they are equivalent to the remote ones.
+func (m *LinuxDirectoryModel) applyLinuxDirectory(directory *LinuxDirectory) {
+	m.Path = types.StringValue(directory.Path)
+
+	if m.Mode.IsNull() || m.Mode.IsUnknown() || !file.ModeEqual(m.Mode.ValueString(), directory.Mode) {
+		m.Mode = types.StringValue(directory.Mode)
+	}
+	if m.Owner.IsNull() || m.Owner.IsUnknown() || !file.OwnerEqual(m.Owner.ValueString(), directory.Owner, directory.Uid) {
+		m.Owner = types.StringValue(directory.Owner)
+	}
+	if m.Group.IsNull() || m.Group.IsUnknown() || !file.OwnerEqual(m.Group.ValueString(), directory.Group, directory.Gid) {
+		m.Group = types.StringValue(directory.Group)
+	}
+}
+
+func unmanagedChildren(children []string, keep []string) []string {
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+
+	unmanaged := []string{}
+	for _, child := range children {
+		if !kept[child] {
+			unmanaged = append(unmanaged, child)
+		}
+	}
+	return unmanaged
+}
+
+func Get(linuxCtx util.LinuxContext, directoryPath string) (*LinuxDirectory, *util.CommonError) {
+	stat, commonError := file.Stat(linuxCtx, directoryPath)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if stat == nil {
+		return nil, nil
+	}
+	if stat.Type != "directory" {
+		diagnostic := diag.NewErrorDiagnostic("Not a directory", fmt.Sprintf("\"%s\" is %s", directoryPath, stat.Type))
+		return nil, &util.CommonError{
+			Diagnostics: diag.Diagnostics{diagnostic},
+		}
+	}
+
+	// Children are separated by NUL, the only byte that cannot be part of a file name.
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("find", directoryPath, "-mindepth", "1", "-maxdepth", "1", "-print0"), nil)
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	children := []string{}
+	for _, child := range strings.Split(result.Stdout, "\x00") {
+		if child != "" {
+			children = append(children, path.Base(child))
+		}
+	}
+
+	return &LinuxDirectory{
+		Path:     directoryPath,
+		Mode:     stat.Mode,
+		Uid:      stat.Uid,
+		Owner:    stat.Owner,
+		Gid:      stat.Gid,
+		Group:    stat.Group,
+		Children: children,
+	}, nil
+}
+
+// hasOwnershipDrift reports whether any entry below directoryPath is not owned by owner and group.
+func hasOwnershipDrift(linuxCtx util.LinuxContext, directoryPath string, owner string, group string) (bool, *util.CommonError) {
+	conditions := []string{}
+	if owner != "" {
+		conditions = append(conditions, "!", "-user", owner)
+	}
+	if group != "" {
+		if len(conditions) > 0 {
+			conditions = append(conditions, "-o")
+		}
+		conditions = append(conditions, "!", "-group", group)
+	}
+	if len(conditions) == 0 {
+		return false, nil
+	}
+
+	argv := append([]string{"find", directoryPath, "("}, conditions...)
+	argv = append(argv, ")", "-print", "-quit")
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
+	if commonError != nil {
+		return false, commonError
+	}
+
+	return strings.TrimSpace(result.Stdout) != "", nil
+}
+
+func removeChildren(linuxCtx util.LinuxContext, directoryPath string, children []string) *util.CommonError {
+	for _, child := range children {
+		_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("rm", "-rf", "--", path.Join(directoryPath, child)), nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+	return nil
+}
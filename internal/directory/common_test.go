@@ -0,0 +1,18 @@
+package directory
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestUnmanagedChildren(t *testing.T) {
+	children := []string{"managed.conf", "stale.conf", ".hidden"}
+	keep := []string{"managed.conf"}
+
+	assert.DeepEqual(t, []string{"stale.conf", ".hidden"}, unmanagedChildren(children, keep))
+}
+
+func TestUnmanagedChildrenEmpty(t *testing.T) {
+	assert.DeepEqual(t, []string{}, unmanagedChildren([]string{}, []string{"managed.conf"}))
+}
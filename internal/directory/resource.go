@@ -0,0 +1,360 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-linux/internal/file"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                   = &directoryResource{}
+	_ resource.ResourceWithConfigure      = &directoryResource{}
+	_ resource.ResourceWithImportState    = &directoryResource{}
+	_ resource.ResourceWithValidateConfig = &directoryResource{}
+)
+
+func NewDirectoryResource() resource.Resource {
+	return &directoryResource{}
+}
+
+type directoryResource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (r *directoryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_directory"
+}
+
+func (r *directoryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Description: "Absolute path of the directory. Creating the resource fails when it already exists, which must be imported instead",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"mode": schema.StringAttribute{
+				Description: "Permission bits of the directory in octal notation",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Description: "Owner of the directory, either name or uid",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group": schema.StringAttribute{
+				Description: "Group of the directory, either name or gid",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recursive": schema.BoolAttribute{
+				Description: "Apply `owner` and `group` to every entry below the directory",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"purge": schema.BoolAttribute{
+				Description: "Delete every child that is not listed in `keep`. The directory is removed with its content on destroy",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"keep": schema.SetAttribute{
+				Description: "Names of children that are preserved by `purge`, such as files managed by `linux_file`",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *directoryResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config LinuxDirectoryModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Mode.IsNull() && !config.Mode.IsUnknown() {
+		if _, err := file.ParseMode(config.Mode.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("mode"),
+				"Invalid mode",
+				fmt.Sprintf("Mode should be in octal notation such as \"0755\": %v", err),
+			)
+		}
+	}
+}
+
+// apply sets mode and ownership of the plan, purges unmanaged children and returns the resulting directory.
+func (r *directoryResource) apply(ctx context.Context, linuxCtx util.LinuxContext, plan *LinuxDirectoryModel) (*LinuxDirectory, diag.Diagnostics) {
+	directoryPath := plan.Path.ValueString()
+
+	mode := ""
+	if !plan.Mode.IsUnknown() && !plan.Mode.IsNull() {
+		mode = plan.Mode.ValueString()
+	}
+	owner := ""
+	if !plan.Owner.IsUnknown() && !plan.Owner.IsNull() {
+		owner = plan.Owner.ValueString()
+	}
+	group := ""
+	if !plan.Group.IsUnknown() && !plan.Group.IsNull() {
+		group = plan.Group.ValueString()
+	}
+	commonError := sshUtil.SetPermissions(linuxCtx, directoryPath, mode, owner, group, plan.Recursive.ValueBool())
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+
+	directory, commonError := Get(linuxCtx, directoryPath)
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+	if directory == nil {
+		return nil, nil
+	}
+
+	if plan.Purge.ValueBool() {
+		keep := []string{}
+		diags := plan.Keep.ElementsAs(ctx, &keep, false)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		commonError = removeChildren(linuxCtx, directoryPath, unmanagedChildren(directory.Children, keep))
+		if commonError != nil {
+			return nil, commonError.Diagnostics
+		}
+	}
+
+	return directory, nil
+}
+
+func (r *directoryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan LinuxDirectoryModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	if plan.Path.IsUnknown() || plan.Path.IsNull() || plan.Path.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Empty path is not allowed",
+			"Please specify a valid path",
+		)
+		return
+	}
+
+	// An existing directory is never adopted, since recursive and purge would rewrite or delete its content.
+	stat, commonError := file.Stat(linuxCtx, plan.Path.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if stat != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Path already exists",
+			fmt.Sprintf("\"%s\" already exists on the server. Import it with `terraform import` to manage it", plan.Path.ValueString()),
+		)
+		return
+	}
+
+	_, _, commonError = sshUtil.RunCommand(linuxCtx, sshUtil.Command("mkdir", "-p", "--", plan.Path.ValueString()), nil)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	directory, diags := r.apply(ctx, linuxCtx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if directory == nil {
+		resp.Diagnostics.AddError("Failed to create directory", "Directory not exists after creation request")
+		return
+	}
+
+	plan.applyLinuxDirectory(directory)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *directoryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxDirectoryModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	directory, commonError := Get(linuxCtx, state.Path.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if directory == nil {
+		resp.State.RemoveResource(linuxCtx.Ctx)
+		return
+	}
+
+	if state.Recursive.IsNull() {
+		state.Recursive = types.BoolValue(false)
+	}
+	if state.Purge.IsNull() {
+		state.Purge = types.BoolValue(false)
+	}
+
+	// Recursive ownership and purge are reported as disabled when they no longer hold,
+	// so that the next plan converges them again.
+	if state.Recursive.ValueBool() {
+		owner := ""
+		if !state.Owner.IsNull() {
+			owner = state.Owner.ValueString()
+		}
+		group := ""
+		if !state.Group.IsNull() {
+			group = state.Group.ValueString()
+		}
+		drifted, commonError := hasOwnershipDrift(linuxCtx, directory.Path, owner, group)
+		if commonError != nil {
+			resp.Diagnostics.Append(commonError.Diagnostics...)
+			return
+		}
+		if drifted {
+			state.Recursive = types.BoolValue(false)
+		}
+	}
+
+	if state.Purge.ValueBool() {
+		keep := []string{}
+		diags = state.Keep.ElementsAs(linuxCtx.Ctx, &keep, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if len(unmanagedChildren(directory.Children, keep)) != 0 {
+			state.Purge = types.BoolValue(false)
+		}
+	}
+
+	state.applyLinuxDirectory(directory)
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *directoryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var plan LinuxDirectoryModel
+	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	directory, diags := r.apply(ctx, linuxCtx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if directory == nil {
+		resp.Diagnostics.AddError("Failed to update directory", "Directory not exists after update request")
+		return
+	}
+
+	plan.applyLinuxDirectory(directory)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *directoryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxDirectoryModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Path.IsUnknown() || state.Path.IsNull() || state.Path.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Empty path is not allowed",
+			"Please specify a valid path",
+		)
+		return
+	}
+
+	// Without purge the directory is only removed when empty, so unmanaged data is never lost.
+	argv := []string{"rmdir"}
+	if state.Purge.ValueBool() {
+		argv = []string{"rm", "-rf"}
+	}
+	argv = append(argv, "--", state.Path.ValueString())
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+}
+
+func (r *directoryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *directoryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("path"), req, resp)
+}
@@ -0,0 +1,250 @@
+package file
+
+import (
+	"context"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+)
+
+var (
+	_ resource.Resource                   = &fileAclResource{}
+	_ resource.ResourceWithConfigure      = &fileAclResource{}
+	_ resource.ResourceWithImportState    = &fileAclResource{}
+	_ resource.ResourceWithValidateConfig = &fileAclResource{}
+)
+
+func NewFileAclResource() resource.Resource {
+	return &fileAclResource{}
+}
+
+type fileAclResource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (r *fileAclResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file_acl"
+}
+
+func (r *fileAclResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	faclLinesSchema := schema.SetNestedAttribute{
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"id": schema.Int64Attribute{
+					Description: "Numeric uid or gid of the entry",
+					Required:    true,
+				},
+				"permission": schema.Int64Attribute{
+					Description: "Permission of the entry in octal notation, from 0 to 7",
+					Required:    true,
+				},
+			},
+		},
+	}
+	faclAttributes := func() map[string]schema.Attribute {
+		return map[string]schema.Attribute{
+			"user": schema.Int64Attribute{
+				Description: "Permission of the owning user",
+				Required:    true,
+			},
+			"group": schema.Int64Attribute{
+				Description: "Permission of the owning group",
+				Required:    true,
+			},
+			"other": schema.Int64Attribute{
+				Description: "Permission of others",
+				Required:    true,
+			},
+			"mask": schema.Int64Attribute{
+				Description: "Upper bound of permissions granted to named entries and the owning group. Calculated by setfacl when omitted",
+				Optional:    true,
+				Computed:    true,
+			},
+			"users":  faclLinesSchema,
+			"groups": faclLinesSchema,
+		}
+	}
+
+	attributes := faclAttributes()
+	attributes["path"] = schema.StringAttribute{
+		Description: "Absolute path of the file or directory",
+		Required:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+	attributes["default"] = schema.SingleNestedAttribute{
+		Description: "Default ACL inherited by new entries of a directory. Removed when omitted",
+		Optional:    true,
+		Attributes:  faclAttributes(),
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Manages the whole POSIX ACL of a file. Entries that are not declared are removed",
+		Attributes:  attributes,
+	}
+}
+
+func (r *fileAclResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config LinuxFileAclModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := formatFaclSpec(config.toFacl()); err != nil {
+		resp.Diagnostics.AddError("Invalid ACL", err.Error())
+	}
+}
+
+func (r *fileAclResource) apply(linuxCtx util.LinuxContext, plan *LinuxFileAclModel) (*Facl, *util.CommonError) {
+	filePath := plan.Path.ValueString()
+
+	commonError := setFacl(linuxCtx, filePath, plan.toFacl())
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	return getFacl(linuxCtx, filePath)
+}
+
+func (r *fileAclResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan LinuxFileAclModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	if plan.Path.IsUnknown() || plan.Path.IsNull() || plan.Path.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Empty path is not allowed",
+			"Please specify a valid path",
+		)
+		return
+	}
+
+	facl, commonError := r.apply(linuxCtx, &plan)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	plan.applyFacl(facl)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *fileAclResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxFileAclModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stat, commonError := Stat(linuxCtx, state.Path.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if stat == nil {
+		resp.State.RemoveResource(linuxCtx.Ctx)
+		return
+	}
+
+	facl, commonError := getFacl(linuxCtx, stat.Path)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	state.applyFacl(facl)
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *fileAclResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var plan LinuxFileAclModel
+	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	facl, commonError := r.apply(linuxCtx, &plan)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	plan.applyFacl(facl)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *fileAclResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxFileAclModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Path.IsUnknown() || state.Path.IsNull() || state.Path.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Empty path is not allowed",
+			"Please specify a valid path",
+		)
+		return
+	}
+
+	commonError := removeFacl(linuxCtx, state.Path.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+}
+
+func (r *fileAclResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *fileAclResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("path"), req, resp)
+}
@@ -1,8 +1,14 @@
 package file
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"terraform-provider-linux/internal/util"
@@ -13,44 +19,267 @@ import (
 )
 
 type LinuxFile struct {
-	Path string
-	Type string
-	Acl  *Facl
+	Path     string
+	Type     string
+	Mode     string
+	Uid      int64
+	Owner    string
+	Gid      int64
+	Group    string
+	Checksum string
+	Acl      *Facl
 }
 
 type LinuxFileModel struct {
-	Path types.String `tfsdk:"path"`
-	Type types.String `tfsdk:"type"`
-	Acl  *FaclModel   `tfsdk:"acl"`
+	Path   types.String `tfsdk:"path"`
+	Type   types.String `tfsdk:"type"`
+	Mode   types.String `tfsdk:"mode"`
+	Owner  types.String `tfsdk:"owner"`
+	Group  types.String `tfsdk:"group"`
+	Sha256 types.String `tfsdk:"sha256"`
+	Acl    *FaclModel   `tfsdk:"acl"`
 }
 
 func NewLinuxFileModel(linuxFile *LinuxFile) LinuxFileModel {
 	return LinuxFileModel{
-		Path: types.StringValue(linuxFile.Path),
-		Type: types.StringValue(linuxFile.Type),
-		Acl:  newFaclModel(linuxFile.Acl),
+		Path:   types.StringValue(linuxFile.Path),
+		Type:   types.StringValue(linuxFile.Type),
+		Mode:   types.StringValue(linuxFile.Mode),
+		Owner:  types.StringValue(linuxFile.Owner),
+		Group:  types.StringValue(linuxFile.Group),
+		Sha256: types.StringValue(linuxFile.Checksum),
+		Acl:    newFaclModel(linuxFile.Acl),
 	}
 }
 
+type LinuxFileResourceModel struct {
+	Path          types.String `tfsdk:"path"`
+	Content       types.String `tfsdk:"content"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	Source        types.String `tfsdk:"source"`
+	Mode          types.String `tfsdk:"mode"`
+	Owner         types.String `tfsdk:"owner"`
+	Group         types.String `tfsdk:"group"`
+	Sha256        types.String `tfsdk:"sha256"`
+}
+
+// contentReader opens the desired content from either content, content_base64 or the local source file.
+// The returned reader streams the data so that large sources are never held in memory.
+func (m *LinuxFileResourceModel) contentReader() (io.ReadCloser, bool, error) {
+	if !m.Content.IsNull() && !m.Content.IsUnknown() {
+		return io.NopCloser(strings.NewReader(m.Content.ValueString())), true, nil
+	}
+	if !m.ContentBase64.IsNull() && !m.ContentBase64.IsUnknown() {
+		return io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(m.ContentBase64.ValueString()))), true, nil
+	}
+	if !m.Source.IsNull() && !m.Source.IsUnknown() {
+		source, err := os.Open(m.Source.ValueString())
+		if err != nil {
+			return nil, false, err
+		}
+		return source, true, nil
+	}
+	return nil, false, nil
+}
+
+// desiredChecksum returns the SHA-256 checksum of the desired content.
+func (m *LinuxFileResourceModel) desiredChecksum() (string, bool, error) {
+	content, ok, err := m.contentReader()
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	defer content.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", false, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), true, nil
+}
+
+// applyLinuxFile updates the model with the state of the file on the server.
+// Configured values are kept when they are equivalent to the remote ones, and content is
+// cleared when the remote checksum no longer matches so that Terraform plans a rewrite.
+func (m *LinuxFileResourceModel) applyLinuxFile(linuxFile *LinuxFile) {
+	m.Path = types.StringValue(linuxFile.Path)
+
+	if m.Mode.IsNull() || m.Mode.IsUnknown() || !ModeEqual(m.Mode.ValueString(), linuxFile.Mode) {
+		m.Mode = types.StringValue(linuxFile.Mode)
+	}
+	if m.Owner.IsNull() || m.Owner.IsUnknown() || !OwnerEqual(m.Owner.ValueString(), linuxFile.Owner, linuxFile.Uid) {
+		m.Owner = types.StringValue(linuxFile.Owner)
+	}
+	if m.Group.IsNull() || m.Group.IsUnknown() || !OwnerEqual(m.Group.ValueString(), linuxFile.Group, linuxFile.Gid) {
+		m.Group = types.StringValue(linuxFile.Group)
+	}
+
+	checksum, ok, err := m.desiredChecksum()
+	if err != nil || (ok && checksum != linuxFile.Checksum) {
+		m.Content = types.StringNull()
+		m.ContentBase64 = types.StringNull()
+		m.Source = types.StringNull()
+	}
+	m.Sha256 = types.StringValue(linuxFile.Checksum)
+}
+
 type Facl struct {
-	User  *FaclLine
-	Group *FaclLine
-	Other *FaclLine
+	User    *FaclLine
+	Group   *FaclLine
+	Other   *FaclLine
+	Mask    *FaclLine
+	Users   []*FaclLine
+	Groups  []*FaclLine
+	Default *Facl
 }
 type FaclModel struct {
-	User  *FaclLineModel `tfsdk:"user"`
-	Group *FaclLineModel `tfsdk:"group"`
-	Other *FaclLineModel `tfsdk:"other"`
+	User    *FaclLineModel    `tfsdk:"user"`
+	Group   *FaclLineModel    `tfsdk:"group"`
+	Other   *FaclLineModel    `tfsdk:"other"`
+	Mask    *FaclLineModel    `tfsdk:"mask"`
+	Users   []*FaclLineModel  `tfsdk:"users"`
+	Groups  []*FaclLineModel  `tfsdk:"groups"`
+	Default *FaclDefaultModel `tfsdk:"default"`
+}
+
+// FaclDefaultModel holds the default ACL of a directory, which has the same entries as FaclModel.
+type FaclDefaultModel struct {
+	User   *FaclLineModel   `tfsdk:"user"`
+	Group  *FaclLineModel   `tfsdk:"group"`
+	Other  *FaclLineModel   `tfsdk:"other"`
+	Mask   *FaclLineModel   `tfsdk:"mask"`
+	Users  []*FaclLineModel `tfsdk:"users"`
+	Groups []*FaclLineModel `tfsdk:"groups"`
 }
 
 func newFaclModel(facl *Facl) *FaclModel {
+	var defaultModel *FaclDefaultModel
+	if facl.Default != nil {
+		defaultModel = &FaclDefaultModel{
+			User:   newFaclLineModel(facl.Default.User),
+			Group:  newFaclLineModel(facl.Default.Group),
+			Other:  newFaclLineModel(facl.Default.Other),
+			Mask:   newFaclLineModel(facl.Default.Mask),
+			Users:  newFaclLineModels(facl.Default.Users),
+			Groups: newFaclLineModels(facl.Default.Groups),
+		}
+	}
+
 	return &FaclModel{
-		User:  newFaclLineModel(facl.User),
-		Group: newFaclLineModel(facl.Group),
-		Other: newFaclLineModel(facl.Other),
+		User:    newFaclLineModel(facl.User),
+		Group:   newFaclLineModel(facl.Group),
+		Other:   newFaclLineModel(facl.Other),
+		Mask:    newFaclLineModel(facl.Mask),
+		Users:   newFaclLineModels(facl.Users),
+		Groups:  newFaclLineModels(facl.Groups),
+		Default: defaultModel,
+	}
+}
+
+type LinuxFileAclModel struct {
+	Path    types.String              `tfsdk:"path"`
+	User    types.Int64               `tfsdk:"user"`
+	Group   types.Int64               `tfsdk:"group"`
+	Other   types.Int64               `tfsdk:"other"`
+	Mask    types.Int64               `tfsdk:"mask"`
+	Users   []FaclLineModel           `tfsdk:"users"`
+	Groups  []FaclLineModel           `tfsdk:"groups"`
+	Default *LinuxFileAclDefaultModel `tfsdk:"default"`
+}
+
+type LinuxFileAclDefaultModel struct {
+	User   types.Int64     `tfsdk:"user"`
+	Group  types.Int64     `tfsdk:"group"`
+	Other  types.Int64     `tfsdk:"other"`
+	Mask   types.Int64     `tfsdk:"mask"`
+	Users  []FaclLineModel `tfsdk:"users"`
+	Groups []FaclLineModel `tfsdk:"groups"`
+}
+
+func newFaclFromModel(user types.Int64, group types.Int64, other types.Int64, mask types.Int64, users []FaclLineModel, groups []FaclLineModel) *Facl {
+	newFaclLine := func(id int64, permission types.Int64) *FaclLine {
+		if permission.IsNull() || permission.IsUnknown() {
+			return nil
+		}
+		return &FaclLine{
+			Id:         id,
+			Permission: permission.ValueInt64(),
+		}
+	}
+	newFaclLines := func(models []FaclLineModel) []*FaclLine {
+		faclLines := []*FaclLine{}
+		for _, model := range models {
+			if faclLine := newFaclLine(model.Id.ValueInt64(), model.Permission); faclLine != nil {
+				faclLines = append(faclLines, faclLine)
+			}
+		}
+		return faclLines
+	}
+
+	return &Facl{
+		User:   newFaclLine(-1, user),
+		Group:  newFaclLine(-1, group),
+		Other:  newFaclLine(-1, other),
+		Mask:   newFaclLine(-1, mask),
+		Users:  newFaclLines(users),
+		Groups: newFaclLines(groups),
 	}
 }
 
+// toFacl converts the configured ACL, leaving the mask out when it should be calculated by setfacl.
+func (m *LinuxFileAclModel) toFacl() *Facl {
+	facl := newFaclFromModel(m.User, m.Group, m.Other, m.Mask, m.Users, m.Groups)
+	if m.Default != nil {
+		facl.Default = newFaclFromModel(m.Default.User, m.Default.Group, m.Default.Other, m.Default.Mask, m.Default.Users, m.Default.Groups)
+	}
+	return facl
+}
+
+// applyFacl updates the model with the ACL on the server. Named entries are sorted by id so that
+// the result does not depend on the order getfacl prints them in.
+func (m *LinuxFileAclModel) applyFacl(facl *Facl) {
+	permissionValue := func(faclLine *FaclLine) types.Int64 {
+		if faclLine == nil {
+			return types.Int64Null()
+		}
+		return types.Int64Value(faclLine.Permission)
+	}
+	faclLineModels := func(current []FaclLineModel, faclLines []*FaclLine) []FaclLineModel {
+		if len(faclLines) == 0 && current == nil {
+			return nil
+		}
+		sorted := append([]*FaclLine{}, faclLines...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Id < sorted[j].Id
+		})
+		models := []FaclLineModel{}
+		for _, faclLine := range sorted {
+			models = append(models, *newFaclLineModel(faclLine))
+		}
+		return models
+	}
+
+	m.User = permissionValue(facl.User)
+	m.Group = permissionValue(facl.Group)
+	m.Other = permissionValue(facl.Other)
+	m.Mask = permissionValue(facl.Mask)
+	m.Users = faclLineModels(m.Users, facl.Users)
+	m.Groups = faclLineModels(m.Groups, facl.Groups)
+
+	if facl.Default == nil {
+		m.Default = nil
+		return
+	}
+	if m.Default == nil {
+		m.Default = &LinuxFileAclDefaultModel{}
+	}
+	m.Default.User = permissionValue(facl.Default.User)
+	m.Default.Group = permissionValue(facl.Default.Group)
+	m.Default.Other = permissionValue(facl.Default.Other)
+	m.Default.Mask = permissionValue(facl.Default.Mask)
+	m.Default.Users = faclLineModels(m.Default.Users, facl.Default.Users)
+	m.Default.Groups = faclLineModels(m.Default.Groups, facl.Default.Groups)
+}
+
 type FaclLine struct {
 	Id         int64
 	Permission int64
@@ -61,12 +290,23 @@ type FaclLineModel struct {
 }
 
 func newFaclLineModel(faclLine *FaclLine) *FaclLineModel {
+	if faclLine == nil {
+		return nil
+	}
 	return &FaclLineModel{
 		Id:         types.Int64Value(faclLine.Id),
 		Permission: types.Int64Value(faclLine.Permission),
 	}
 }
 
+func newFaclLineModels(faclLines []*FaclLine) []*FaclLineModel {
+	models := []*FaclLineModel{}
+	for _, faclLine := range faclLines {
+		models = append(models, newFaclLineModel(faclLine))
+	}
+	return models
+}
+
 type PermissionType int64
 
 const (
@@ -113,145 +353,389 @@ func parsePermissionTypeString(in string, permissionType PermissionType) (string
 	return "", 0, errors.New(fmt.Sprintf("Invalid permission type string \"%s\" provided", in))
 }
 
-func parseFacl(content string) (*Facl, error) {
-	lines := strings.Split(content, "\n")
+func parsePermissionString(in string) (PermissionType, error) {
+	permissionString := in
+	var err error
 
-	parsePermissionString := func(in string) (PermissionType, error) {
-		permissionString := in
-		var err error
+	permission := NoPermission
+	permissionForType := NoPermission
 
-		permission := NoPermission
-		permissionForType := NoPermission
+	permissionString, permissionForType, err = parsePermissionTypeString(permissionString, Read)
+	if err != nil {
+		return Invalid, err
+	}
+	permission = permission + permissionForType
 
-		permissionString, permissionForType, err = parsePermissionTypeString(permissionString, Read)
-		if err != nil {
-			return Invalid, err
-		}
-		permission = permission + permissionForType
+	permissionString, permissionForType, err = parsePermissionTypeString(permissionString, Write)
+	if err != nil {
+		return Invalid, err
+	}
+	permission = permission + permissionForType
 
-		permissionString, permissionForType, err = parsePermissionTypeString(permissionString, Write)
-		if err != nil {
-			return Invalid, err
-		}
-		permission = permission + permissionForType
+	_, permissionForType, err = parsePermissionTypeString(permissionString, Execute)
+	if err != nil {
+		return Invalid, err
+	}
+	permission = permission + permissionForType
 
-		permissionString, permissionForType, err = parsePermissionTypeString(permissionString, Execute)
+	return permission, nil
+}
+
+// formatPermission is the inverse of parsePermissionString, e.g. 5 becomes "r-x".
+func formatPermission(permission int64) (string, error) {
+	if permission < 0 || permission > 7 {
+		return "", errors.New(fmt.Sprintf("Permission %d is out of range", permission))
+	}
+
+	formatted := ""
+	for _, permissionType := range []PermissionType{Read, Write, Execute} {
+		if PermissionType(permission)&permissionType == 0 {
+			permissionType = NoPermission
+		}
+		permissionTypeString, err := mapPermissionTypeToString(permissionType)
 		if err != nil {
-			return Invalid, err
+			return "", err
 		}
-		permission = permission + permissionForType
-
-		return permission, nil
+		formatted = formatted + permissionTypeString
 	}
+	return formatted, nil
+}
 
-	var userAcl *FaclLine
-	var groupAcl *FaclLine
-	var otherAcl *FaclLine
+// parseFacl parses the output of "getfacl -n -p -E", including named entries, mask and default ACL.
+func parseFacl(content string) (*Facl, error) {
+	lines := strings.Split(content, "\n")
+
+	var ownerId int64 = -1
+	var groupId int64 = -1
+	acl := &Facl{
+		Users:  []*FaclLine{},
+		Groups: []*FaclLine{},
+	}
+	defaultAcl := &Facl{
+		Users:  []*FaclLine{},
+		Groups: []*FaclLine{},
+	}
+	hasDefault := false
 
 	for _, line := range lines {
-		if after, found := strings.CutPrefix(line, "USER"); found {
-			splitted := strings.Fields(after)
-			if len(splitted) < 2 {
-				return nil, errors.New("Invalid ACL line format")
-			}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
 
-			id, err := strconv.ParseInt(splitted[0], 10, 64)
-			if err != nil {
-				return nil, err
+		if after, found := strings.CutPrefix(line, "#"); found {
+			splitted := strings.SplitN(after, ":", 2)
+			if len(splitted) != 2 {
+				continue
 			}
 
-			permission, err := parsePermissionString(splitted[1])
+			var err error
+			switch strings.TrimSpace(splitted[0]) {
+			case "owner":
+				ownerId, err = strconv.ParseInt(strings.TrimSpace(splitted[1]), 10, 64)
+			case "group":
+				groupId, err = strconv.ParseInt(strings.TrimSpace(splitted[1]), 10, 64)
+			}
 			if err != nil {
 				return nil, err
 			}
+			continue
+		}
 
-			userAcl = &FaclLine{
-				Id:         id,
-				Permission: int64(permission),
-			}
+		target := acl
+		if after, found := strings.CutPrefix(line, "default:"); found {
+			target = defaultAcl
+			hasDefault = true
+			line = after
 		}
-		if after, found := strings.CutPrefix(line, "GROUP"); found {
-			splitted := strings.Fields(after)
-			if len(splitted) < 2 {
-				return nil, errors.New("Invalid ACL line format")
-			}
 
-			id, err := strconv.ParseInt(splitted[0], 10, 64)
-			if err != nil {
-				return nil, err
-			}
+		splitted := strings.SplitN(line, ":", 3)
+		if len(splitted) != 3 {
+			return nil, errors.New(fmt.Sprintf("Invalid ACL line format \"%s\"", line))
+		}
+		fields := strings.Fields(splitted[2])
+		if len(fields) < 1 {
+			return nil, errors.New(fmt.Sprintf("Missing permission in ACL line \"%s\"", line))
+		}
+		permission, err := parsePermissionString(fields[0])
+		if err != nil {
+			return nil, err
+		}
 
-			permission, err := parsePermissionString(splitted[1])
+		var id int64 = -1
+		if splitted[1] != "" {
+			id, err = strconv.ParseInt(splitted[1], 10, 64)
 			if err != nil {
 				return nil, err
 			}
-
-			groupAcl = &FaclLine{
-				Id:         id,
-				Permission: int64(permission),
-			}
 		}
-		if after, found := strings.CutPrefix(line, "other"); found {
-			splitted := strings.Fields(after)
-			if len(splitted) < 1 {
-				return nil, errors.New("Invalid ACL line format for \"other\"")
-			}
 
-			permission, err := parsePermissionString(splitted[0])
-			if err != nil {
-				return nil, err
+		switch splitted[0] {
+		case "user":
+			if splitted[1] == "" {
+				target.User = &FaclLine{Id: ownerId, Permission: int64(permission)}
+			} else {
+				target.Users = append(target.Users, &FaclLine{Id: id, Permission: int64(permission)})
 			}
-
-			otherAcl = &FaclLine{
-				Id:         -1,
-				Permission: int64(permission),
+		case "group":
+			if splitted[1] == "" {
+				target.Group = &FaclLine{Id: groupId, Permission: int64(permission)}
+			} else {
+				target.Groups = append(target.Groups, &FaclLine{Id: id, Permission: int64(permission)})
 			}
+		case "mask":
+			target.Mask = &FaclLine{Id: -1, Permission: int64(permission)}
+		case "other":
+			target.Other = &FaclLine{Id: -1, Permission: int64(permission)}
+		default:
+			return nil, errors.New(fmt.Sprintf("Unknown ACL tag \"%s\"", splitted[0]))
 		}
 	}
 
-	return &Facl{
-		User:  userAcl,
-		Group: groupAcl,
-		Other: otherAcl,
+	if acl.User == nil || acl.Group == nil || acl.Other == nil {
+		return nil, errors.New("Missing base ACL entries")
+	}
+	if hasDefault {
+		acl.Default = defaultAcl
+	}
+
+	return acl, nil
+}
+
+// ParseMode parses octal permission bits such as "644" or "0644".
+func ParseMode(mode string) (int64, error) {
+	parsed, err := strconv.ParseInt(mode, 8, 64)
+	if err != nil {
+		return 0, err
+	}
+	if parsed < 0 || parsed > 07777 {
+		return 0, errors.New(fmt.Sprintf("Mode \"%s\" is out of range", mode))
+	}
+	return parsed, nil
+}
+
+// ModeEqual reports whether two octal mode strings describe the same permission bits.
+func ModeEqual(a string, b string) bool {
+	parsedA, err := ParseMode(a)
+	if err != nil {
+		return false
+	}
+	parsedB, err := ParseMode(b)
+	if err != nil {
+		return false
+	}
+	return parsedA == parsedB
+}
+
+// OwnerEqual reports whether the configured owner or group matches either the name or the numeric id on the server.
+func OwnerEqual(configured string, name string, id int64) bool {
+	return configured == name || configured == strconv.FormatInt(id, 10)
+}
+
+func parseStat(content string) (*LinuxFile, error) {
+	splitted := strings.Split(strings.TrimSpace(content), ":")
+	if len(splitted) != 6 {
+		return nil, errors.New(fmt.Sprintf("Invalid stat output \"%s\"", content))
+	}
+
+	mode, err := ParseMode(splitted[1])
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	gid, err := strconv.ParseInt(splitted[4], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	fileType := splitted[0]
+	switch fileType {
+	case "regular file", "regular empty file":
+		fileType = "file"
+	}
+
+	return &LinuxFile{
+		Type:  fileType,
+		Mode:  fmt.Sprintf("%04o", mode),
+		Uid:   uid,
+		Owner: splitted[3],
+		Gid:   gid,
+		Group: splitted[5],
 	}, nil
 }
 
-func Get(linuxCtx util.LinuxContext, file *LinuxFile) (*LinuxFile, *util.CommonError) {
-	errorhandler := func(out []byte, err error) (util.Status, *util.CommonError) {
-		if err != nil {
-			switch err.Error() {
-			case "Process exited with status 1":
-				diagnostic := diag.NewErrorDiagnostic(
-					"Path not found",
-					"Please check path",
-				)
-				return util.Success, &util.CommonError{
-					Error: err,
-					Diagnostics: diag.Diagnostics{
-						diagnostic,
-					},
-				}
-			}
+// Stat returns type, mode and ownership of filePath, or nil if it does not exist.
+// stat also exits with 1 when filePath cannot be accessed, which is an error rather than a missing file,
+// so its message is read in the C locale to tell both apart.
+func Stat(linuxCtx util.LinuxContext, filePath string) (*LinuxFile, *util.CommonError) {
+	notFound := false
+	statErrorhandler := func(result *util.CommandResult, err error) (util.Status, *util.CommonError) {
+		if result.Exited(1) && strings.HasSuffix(strings.TrimSpace(result.Stderr), "No such file or directory") {
+			notFound = true
+			return util.Success, nil
 		}
 		return util.Bottom, nil
 	}
-	_, stdout, commonError := sshUtil.RunCommand(linuxCtx, "getfacl -nt"+" "+file.Path, errorhandler)
+	command := "LC_ALL=C " + sshUtil.Command("stat", "-c", "%F:%a:%u:%U:%g:%G", "--", filePath)
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, command, statErrorhandler)
 	if commonError != nil {
 		return nil, commonError
 	}
-	acl, err := parseFacl(stdout)
+	if notFound {
+		return nil, nil
+	}
+	stat, err := parseStat(result.Stdout)
 	if err != nil {
 		return nil, &util.CommonError{
 			Error: err,
 			Diagnostics: diag.Diagnostics{
-				diag.NewErrorDiagnostic("Failed to parse facl", fmt.Sprintf("Error: %v\nFailed to parse facl content:\n%s", err, stdout)),
+				diag.NewErrorDiagnostic("Failed to parse stat", fmt.Sprintf("Error: %v\nFailed to parse stat content:\n%s", err, result.Stdout)),
 			},
 		}
 	}
+	stat.Path = filePath
+
+	return stat, nil
+}
+
+func getFacl(linuxCtx util.LinuxContext, filePath string) (*Facl, *util.CommonError) {
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("getfacl", "-n", "-p", "-E", "--", filePath), nil)
+	if commonError != nil {
+		return nil, commonError
+	}
+	acl, err := parseFacl(result.Stdout)
+	if err != nil {
+		return nil, &util.CommonError{
+			Error: err,
+			Diagnostics: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed to parse facl", fmt.Sprintf("Error: %v\nFailed to parse facl content:\n%s", err, result.Stdout)),
+			},
+		}
+	}
+	return acl, nil
+}
+
+// formatFaclSpec returns the ACL in the form accepted by "setfacl --set", including the default ACL.
+func formatFaclSpec(facl *Facl) (string, error) {
+	entries := []string{}
+
+	appendEntries := func(prefix string, facl *Facl) error {
+		appendEntry := func(tag string, qualifier string, faclLine *FaclLine) error {
+			if faclLine == nil {
+				return nil
+			}
+			permission, err := formatPermission(faclLine.Permission)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, prefix+tag+":"+qualifier+":"+permission)
+			return nil
+		}
+
+		if err := appendEntry("user", "", facl.User); err != nil {
+			return err
+		}
+		for _, faclLine := range facl.Users {
+			if err := appendEntry("user", strconv.FormatInt(faclLine.Id, 10), faclLine); err != nil {
+				return err
+			}
+		}
+		if err := appendEntry("group", "", facl.Group); err != nil {
+			return err
+		}
+		for _, faclLine := range facl.Groups {
+			if err := appendEntry("group", strconv.FormatInt(faclLine.Id, 10), faclLine); err != nil {
+				return err
+			}
+		}
+		if err := appendEntry("mask", "", facl.Mask); err != nil {
+			return err
+		}
+		return appendEntry("other", "", facl.Other)
+	}
+
+	if err := appendEntries("", facl); err != nil {
+		return "", err
+	}
+	if facl.Default != nil {
+		if err := appendEntries("default:", facl.Default); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(entries, ","), nil
+}
+
+// setFacl replaces the whole ACL of filePath, removing every entry that is not part of facl.
+func setFacl(linuxCtx util.LinuxContext, filePath string, facl *Facl) *util.CommonError {
+	spec, err := formatFaclSpec(facl)
+	if err != nil {
+		return &util.CommonError{
+			Error: err,
+			Diagnostics: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Invalid ACL", err.Error()),
+			},
+		}
+	}
+
+	// "setfacl --set" leaves the default ACL untouched when no default entries are given.
+	if facl.Default == nil {
+		_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("setfacl", "-k", "--", filePath), nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("setfacl", "--set", spec, "--", filePath), nil)
+	return commonError
+}
+
+func removeFacl(linuxCtx util.LinuxContext, filePath string) *util.CommonError {
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("setfacl", "-b", "--", filePath), nil)
+	return commonError
+}
+
+func Get(linuxCtx util.LinuxContext, file *LinuxFile) (*LinuxFile, *util.CommonError) {
+	stat, commonError := Stat(linuxCtx, file.Path)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if stat == nil {
+		return nil, nil
+	}
+
+	acl, commonError := getFacl(linuxCtx, file.Path)
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	checksum := ""
+	if stat.Type == "file" {
+		checksum, commonError = sshUtil.RemoteChecksum(linuxCtx, file.Path)
+		if commonError != nil {
+			return nil, commonError
+		}
+	}
 
 	return &LinuxFile{
-		Path: file.Path,
-		Type: file.Type,
-		Acl:  acl,
+		Path:     file.Path,
+		Type:     stat.Type,
+		Mode:     stat.Mode,
+		Uid:      stat.Uid,
+		Owner:    stat.Owner,
+		Gid:      stat.Gid,
+		Group:    stat.Group,
+		Checksum: checksum,
+		Acl:      acl,
 	}, nil
 }
+
+func remove(linuxCtx util.LinuxContext, filePath string) *util.CommonError {
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("rm", "-f", "--", filePath), nil)
+	return commonError
+}
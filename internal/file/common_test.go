@@ -0,0 +1,138 @@
+package file
+
+import (
+	"context"
+	"strings"
+	"terraform-provider-linux/internal/util"
+	"terraform-provider-linux/internal/util/fake"
+	"terraform-provider-linux/internal/util/sshtest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseStat(t *testing.T) {
+	desired := &LinuxFile{
+		Type:  "file",
+		Mode:  "0644",
+		Uid:   0,
+		Owner: "root",
+		Gid:   100,
+		Group: "users",
+	}
+
+	stat, err := parseStat("regular file:644:0:root:100:users\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, desired, stat)
+}
+
+func TestParseStatDirectory(t *testing.T) {
+	stat, err := parseStat("directory:1777:0:root:0:root")
+	assert.NilError(t, err)
+	assert.Equal(t, "directory", stat.Type)
+	assert.Equal(t, "1777", stat.Mode)
+}
+
+func TestParseStatInvalid(t *testing.T) {
+	_, err := parseStat("stat: cannot stat '/nope': No such file or directory")
+	assert.ErrorContains(t, err, "Invalid stat output")
+}
+
+func TestModeEqual(t *testing.T) {
+	assert.Assert(t, ModeEqual("644", "0644"))
+	assert.Assert(t, !ModeEqual("0644", "0600"))
+	assert.Assert(t, !ModeEqual("rw-r--r--", "0644"))
+}
+
+func TestParseFacl(t *testing.T) {
+	content := `# file: /srv/data
+# owner: 0
+# group: 100
+# flags: -s-
+user::rwx
+user:1000:rw-
+group::r-x
+group:2000:r--
+mask::rwx
+other::---
+default:user::rwx
+default:group::r-x
+default:other::---
+`
+	desired := &Facl{
+		User:   &FaclLine{Id: 0, Permission: 7},
+		Group:  &FaclLine{Id: 100, Permission: 5},
+		Other:  &FaclLine{Id: -1, Permission: 0},
+		Mask:   &FaclLine{Id: -1, Permission: 7},
+		Users:  []*FaclLine{{Id: 1000, Permission: 6}},
+		Groups: []*FaclLine{{Id: 2000, Permission: 4}},
+		Default: &Facl{
+			User:   &FaclLine{Id: 0, Permission: 7},
+			Group:  &FaclLine{Id: 100, Permission: 5},
+			Other:  &FaclLine{Id: -1, Permission: 0},
+			Users:  []*FaclLine{},
+			Groups: []*FaclLine{},
+		},
+	}
+
+	acl, err := parseFacl(content)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, desired, acl)
+}
+
+func TestFormatFaclSpec(t *testing.T) {
+	acl, err := parseFacl("# owner: 0\n# group: 0\nuser::rwx\nuser:1000:rw-\ngroup::r-x\nmask::rwx\nother::r--\ndefault:user::rwx\ndefault:group::---\ndefault:other::---\n")
+	assert.NilError(t, err)
+
+	spec, err := formatFaclSpec(acl)
+	assert.NilError(t, err)
+	assert.Equal(t, "user::rwx,user:1000:rw-,group::r-x,mask::rwx,other::r--,default:user::rwx,default:group::---,default:other::---", spec)
+}
+
+func TestFormatPermissionInvalid(t *testing.T) {
+	_, err := formatPermission(8)
+	assert.ErrorContains(t, err, "out of range")
+}
+
+func TestStat(t *testing.T) {
+	executor := fake.NewExecutor().
+		On("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /etc/hosts", fake.Response{Stdout: "regular file:644:0:root:0:root\n"}).
+		On("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /missing", fake.Response{ExitCode: 1, Stderr: "stat: cannot statx '/missing': No such file or directory\n"})
+	linuxCtx := util.NewLinuxContext(context.Background(), &util.LinuxProviderData{Executor: executor})
+
+	stat, commonError := Stat(linuxCtx, "/etc/hosts")
+	assert.Assert(t, commonError == nil)
+	assert.Equal(t, "/etc/hosts", stat.Path)
+	assert.Equal(t, "0644", stat.Mode)
+
+	stat, commonError = Stat(linuxCtx, "/missing")
+	assert.Assert(t, commonError == nil)
+	assert.Assert(t, stat == nil)
+}
+
+func TestStatPermissionDenied(t *testing.T) {
+	executor := fake.NewExecutor().
+		On("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /root/secret", fake.Response{ExitCode: 1, Stderr: "stat: cannot statx '/root/secret': Permission denied\n"})
+	linuxCtx := util.NewLinuxContext(context.Background(), &util.LinuxProviderData{Executor: executor})
+
+	// A path that cannot be seen is not reported as missing, which would drop it from the state.
+	stat, commonError := Stat(linuxCtx, "/root/secret")
+	assert.Assert(t, commonError != nil)
+	assert.Assert(t, stat == nil)
+	assert.Assert(t, strings.Contains(commonError.Diagnostics[0].Detail(), "Permission denied"), commonError.Diagnostics[0].Detail())
+}
+
+func TestGet(t *testing.T) {
+	server := sshtest.NewServer(t).
+		Handle("LC_ALL=C stat -c %F:%a:%u:%U:%g:%G -- /etc/hosts", sshtest.Response{Stdout: "regular file:644:0:root:0:root\n"}).
+		Handle("getfacl -n -p -E -- /etc/hosts", sshtest.Response{Stdout: "# file: /etc/hosts\n# owner: 0\n# group: 0\nuser::rw-\ngroup::r--\nother::r--\n"}).
+		Handle("sha256sum < /etc/hosts", sshtest.Response{Stdout: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  -\n"})
+
+	file, commonError := Get(server.LinuxContext(t), &LinuxFile{Path: "/etc/hosts"})
+
+	assert.Assert(t, commonError == nil)
+	assert.Equal(t, "file", file.Type)
+	assert.Equal(t, "0644", file.Mode)
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", file.Checksum)
+	assert.Equal(t, int64(6), file.Acl.User.Permission)
+}
@@ -2,6 +2,7 @@ package file
 
 import (
 	"context"
+	"fmt"
 	"terraform-provider-linux/internal/util"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -99,6 +100,22 @@ func (d *fileDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		resp.Diagnostics.Append(commonError.Diagnostics...)
 		return
 	}
+	if file == nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Path not found",
+			"Please check path",
+		)
+		return
+	}
+	if file.Type != file_type {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("type"),
+			"Type mismatch",
+			fmt.Sprintf("Expected \"%s\" to be %s, got %s", file_path, file_type, file.Type),
+		)
+		return
+	}
 
 	state = NewLinuxFileModel(file)
 
@@ -123,6 +140,20 @@ func (*fileDataSource) Schema(_ context.Context, req datasource.SchemaRequest, r
 		},
 	}
 
+	faclLinesSchema := schema.ListNestedAttribute{
+		Computed: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"id": schema.Int64Attribute{
+					Computed: true,
+				},
+				"permission": schema.Int64Attribute{
+					Computed: true,
+				},
+			},
+		},
+	}
+
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"path": schema.StringAttribute{
@@ -132,12 +163,41 @@ func (*fileDataSource) Schema(_ context.Context, req datasource.SchemaRequest, r
 				Description: "Specify type of file. Can be either `file` or `directory`",
 				Required:    true,
 			},
+			"mode": schema.StringAttribute{
+				Description: "Permission bits of the file in octal notation",
+				Computed:    true,
+			},
+			"owner": schema.StringAttribute{
+				Computed: true,
+			},
+			"group": schema.StringAttribute{
+				Computed: true,
+			},
+			"sha256": schema.StringAttribute{
+				Description: "SHA-256 checksum of the file content. Empty for directories",
+				Computed:    true,
+			},
 			"acl": schema.SingleNestedAttribute{
 				Computed: true,
 				Attributes: map[string]schema.Attribute{
-					"user":  faclLineSchema,
-					"group": faclLineSchema,
-					"other": faclLineSchema,
+					"user":   faclLineSchema,
+					"group":  faclLineSchema,
+					"other":  faclLineSchema,
+					"mask":   faclLineSchema,
+					"users":  faclLinesSchema,
+					"groups": faclLinesSchema,
+					"default": schema.SingleNestedAttribute{
+						Description: "Default ACL of a directory. Null when there is none",
+						Computed:    true,
+						Attributes: map[string]schema.Attribute{
+							"user":   faclLineSchema,
+							"group":  faclLineSchema,
+							"other":  faclLineSchema,
+							"mask":   faclLineSchema,
+							"users":  faclLinesSchema,
+							"groups": faclLinesSchema,
+						},
+					},
 				},
 			},
 		},
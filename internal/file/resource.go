@@ -0,0 +1,363 @@
+package file
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                   = &fileResource{}
+	_ resource.ResourceWithConfigure      = &fileResource{}
+	_ resource.ResourceWithImportState    = &fileResource{}
+	_ resource.ResourceWithValidateConfig = &fileResource{}
+)
+
+func NewFileResource() resource.Resource {
+	return &fileResource{}
+}
+
+type fileResource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (r *fileResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file"
+}
+
+func (r *fileResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Description: "Absolute path of the file",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"content": schema.StringAttribute{
+				Description: "Content of the file. Conflicts with `content_base64` and `source`",
+				Optional:    true,
+			},
+			"content_base64": schema.StringAttribute{
+				Description: "Base64 encoded content of the file, for binary data. Conflicts with `content` and `source`",
+				Optional:    true,
+			},
+			"source": schema.StringAttribute{
+				Description: "Path of a local file to upload, streamed over SFTP. Conflicts with `content` and `content_base64`",
+				Optional:    true,
+			},
+			"mode": schema.StringAttribute{
+				Description: "Permission bits of the file in octal notation. New files default to 0600, and rewritten files keep their mode",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Description: "Owner of the file, either name or uid",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group": schema.StringAttribute{
+				Description: "Group of the file, either name or gid",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"sha256": schema.StringAttribute{
+				Description: "SHA-256 checksum of the file content on the server",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *fileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config LinuxFileResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	specified := 0
+	for _, value := range []types.String{config.Content, config.ContentBase64, config.Source} {
+		if !value.IsNull() {
+			specified = specified + 1
+		}
+	}
+	if specified > 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Conflicting content",
+			"Only one of `content`, `content_base64` or `source` can be specified",
+		)
+	}
+	if specified == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Missing content",
+			"One of `content`, `content_base64` or `source` must be specified",
+		)
+	}
+
+	if !config.Mode.IsNull() && !config.Mode.IsUnknown() {
+		if _, err := ParseMode(config.Mode.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("mode"),
+				"Invalid mode",
+				fmt.Sprintf("Mode should be in octal notation such as \"0644\": %v", err),
+			)
+		}
+	}
+
+	if !config.ContentBase64.IsNull() && !config.ContentBase64.IsUnknown() {
+		if _, err := base64.StdEncoding.DecodeString(config.ContentBase64.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_base64"),
+				"Invalid base64 content",
+				err.Error(),
+			)
+		}
+	}
+}
+
+// apply writes content, mode and ownership of the plan and returns the resulting file.
+// Mode and ownership that are not configured are taken from the existing file, so that rewriting
+// the content does not reset them.
+func (r *fileResource) apply(linuxCtx util.LinuxContext, plan *LinuxFileResourceModel, writeRequired bool) (*LinuxFile, *util.CommonError) {
+	filePath := plan.Path.ValueString()
+
+	existing, commonError := Stat(linuxCtx, filePath)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if existing != nil && existing.Type != "file" {
+		err := fmt.Errorf("%s is a %s", filePath, existing.Type)
+		return nil, &util.CommonError{
+			Error: err,
+			Diagnostics: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Path is not a regular file", fmt.Sprintf("Refusing to replace it: %v", err)),
+			},
+		}
+	}
+
+	mode := ""
+	owner := ""
+	group := ""
+	if existing != nil {
+		mode = existing.Mode
+		owner = strconv.FormatInt(existing.Uid, 10)
+		group = strconv.FormatInt(existing.Gid, 10)
+	}
+	if !plan.Mode.IsUnknown() && !plan.Mode.IsNull() {
+		mode = plan.Mode.ValueString()
+	}
+	if !plan.Owner.IsUnknown() && !plan.Owner.IsNull() {
+		owner = plan.Owner.ValueString()
+	}
+	if !plan.Group.IsUnknown() && !plan.Group.IsNull() {
+		group = plan.Group.ValueString()
+	}
+
+	if writeRequired {
+		content, _, err := plan.contentReader()
+		if err != nil {
+			return nil, &util.CommonError{
+				Error: err,
+				Diagnostics: diag.Diagnostics{
+					diag.NewErrorDiagnostic("Failed to open content", err.Error()),
+				},
+			}
+		}
+		defer content.Close()
+
+		// Mode and ownership are set before the rename, so the content is never readable by anyone else.
+		_, commonError := sshUtil.UploadWithOptions(linuxCtx, content, filePath, sshUtil.UploadOptions{
+			Mode:  mode,
+			Owner: owner,
+			Group: group,
+		})
+		if commonError != nil {
+			return nil, commonError
+		}
+	} else {
+		commonError = sshUtil.SetPermissions(linuxCtx, filePath, mode, owner, group, false)
+		if commonError != nil {
+			return nil, commonError
+		}
+	}
+
+	return Get(linuxCtx, &LinuxFile{
+		Path: filePath,
+		Type: "file",
+	})
+}
+
+func (r *fileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan LinuxFileResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	if plan.Path.IsUnknown() || plan.Path.IsNull() || plan.Path.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Empty path is not allowed",
+			"Please specify a valid path",
+		)
+		return
+	}
+
+	file, commonError := r.apply(linuxCtx, &plan, true)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if file == nil {
+		resp.Diagnostics.AddError("Failed to create file", "File not exists after creation request")
+		return
+	}
+
+	plan.applyLinuxFile(file)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *fileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxFileResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	file, commonError := Get(linuxCtx, &LinuxFile{
+		Path: state.Path.ValueString(),
+		Type: "file",
+	})
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	// A directory or link that replaced the file is not adopted. Apply then refuses to overwrite it.
+	if file == nil || file.Type != "file" {
+		resp.State.RemoveResource(linuxCtx.Ctx)
+		return
+	}
+
+	state.applyLinuxFile(file)
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *fileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var plan LinuxFileResourceModel
+	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state LinuxFileResourceModel
+	diags = req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	writeRequired := !plan.Content.Equal(state.Content) || !plan.ContentBase64.Equal(state.ContentBase64) || !plan.Source.Equal(state.Source)
+
+	file, commonError := r.apply(linuxCtx, &plan, writeRequired)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if file == nil {
+		resp.Diagnostics.AddError("Failed to update file", "File not exists after update request")
+		return
+	}
+
+	plan.applyLinuxFile(file)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *fileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxFileResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Path.IsUnknown() || state.Path.IsNull() || state.Path.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Empty path is not allowed",
+			"Please specify a valid path",
+		)
+		return
+	}
+
+	commonError := remove(linuxCtx, state.Path.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+}
+
+func (r *fileResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *fileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("path"), req, resp)
+}
@@ -0,0 +1,155 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type LinuxGroup struct {
+	Name    string
+	Gid     int64
+	Members []string
+}
+
+type LinuxGroupModel struct {
+	Name    types.String `tfsdk:"name"`
+	Gid     types.Int64  `tfsdk:"gid"`
+	Members types.Set    `tfsdk:"members"`
+}
+
+func newMembersValue(members []string) types.Set {
+	elements := []attr.Value{}
+	for _, member := range members {
+		elements = append(elements, types.StringValue(member))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+func NewLinuxGroupModel(group *LinuxGroup) LinuxGroupModel {
+	return LinuxGroupModel{
+		Name:    types.StringValue(group.Name),
+		Gid:     types.Int64Value(group.Gid),
+		Members: newMembersValue(group.Members),
+	}
+}
+
+type LinuxGroupResourceModel struct {
+	Name    types.String `tfsdk:"name"`
+	Gid     types.Int64  `tfsdk:"gid"`
+	Members types.Set    `tfsdk:"members"`
+	System  types.Bool   `tfsdk:"system"`
+}
+
+// applyLinuxGroup updates the model with the group on the server.
+// system only applies at creation and is kept, or defaults to false after import.
+func (m *LinuxGroupResourceModel) applyLinuxGroup(group *LinuxGroup) {
+	if m.System.IsNull() || m.System.IsUnknown() {
+		m.System = types.BoolValue(false)
+	}
+
+	model := NewLinuxGroupModel(group)
+	m.Name = model.Name
+	m.Gid = model.Gid
+	m.Members = model.Members
+}
+
+// members returns the planned members, sorted, or false when they are not known.
+func (m *LinuxGroupResourceModel) members(ctx context.Context) ([]string, bool, diag.Diagnostics) {
+	if m.Members.IsUnknown() || m.Members.IsNull() {
+		return nil, false, nil
+	}
+
+	members := []string{}
+	diags := m.Members.ElementsAs(ctx, &members, false)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+	sort.Strings(members)
+	return members, true, nil
+}
+
+// difference returns the elements of a that are not in b.
+func difference(a []string, b []string) []string {
+	excluded := map[string]bool{}
+	for _, element := range b {
+		excluded[element] = true
+	}
+
+	result := []string{}
+	for _, element := range a {
+		if !excluded[element] {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+// parseMembers parses the comma separated member list of a group entry.
+func parseMembers(field string) []string {
+	members := []string{}
+	for _, member := range strings.Split(strings.TrimSpace(field), ",") {
+		if member != "" {
+			members = append(members, member)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+func Get(linuxCtx util.LinuxContext, name string) (*LinuxGroup, *util.CommonError) {
+	if name == "" {
+		diagnostic := diag.NewErrorDiagnostic("Empty group name", "Please specify group name")
+		return nil, &util.CommonError{
+			Error:       nil,
+			Diagnostics: diag.Diagnostics{diagnostic},
+		}
+	}
+
+	errorhandler := func(result *util.CommandResult, err error) (util.Status, *util.CommonError) {
+		// getent exits with 2 when the key is not found.
+		if result.Exited(2) {
+			return util.Success, nil
+		}
+
+		return util.Bottom, nil
+	}
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("getent", "group", name), errorhandler)
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	getent := strings.Split(strings.TrimSuffix(result.Stdout, "\n"), ":")
+	if len(getent) != 4 {
+		return nil, nil
+	}
+
+	gid, err := strconv.ParseInt(getent[2], 10, 64)
+	if err != nil {
+		diagnostic := diag.NewErrorDiagnostic("Failed to parse getent gid", fmt.Sprint(err.Error()))
+		return nil, &util.CommonError{
+			Error:       err,
+			Diagnostics: diag.Diagnostics{diagnostic},
+		}
+	}
+
+	return &LinuxGroup{
+		Name:    getent[0],
+		Gid:     gid,
+		Members: parseMembers(getent[3]),
+	}, nil
+}
+
+// SetMembers replaces the member list of the group named name.
+func SetMembers(linuxCtx util.LinuxContext, name string, members []string) *util.CommonError {
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("gpasswd", "-M", strings.Join(members, ","), name), nil)
+	return commonError
+}
@@ -0,0 +1,43 @@
+package group
+
+import (
+	"terraform-provider-linux/internal/util/sshtest"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestGet(t *testing.T) {
+	desired := &LinuxGroup{
+		Name:    "wheel",
+		Gid:     10,
+		Members: []string{"alice", "bob"},
+	}
+
+	server := sshtest.NewServer(t).Handle("getent group wheel", sshtest.Response{Stdout: "wheel:x:10:bob,alice\n"})
+	group, err := Get(server.LinuxContext(t), "wheel")
+
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, desired, group)
+}
+
+func TestGetInvalidGroup(t *testing.T) {
+	// getent exits with 2 when the group does not exist.
+	server := sshtest.NewServer(t).Handle("getent group group_not_exists", sshtest.Response{ExitCode: 2})
+	group, err := Get(server.LinuxContext(t), "group_not_exists")
+
+	assert.Assert(t, is.Nil(group))
+	assert.Assert(t, is.Nil(err))
+}
+
+func TestParseMembers(t *testing.T) {
+	assert.DeepEqual(t, []string{}, parseMembers(""))
+	assert.DeepEqual(t, []string{"alice", "bob"}, parseMembers("bob,alice"))
+}
+
+func TestDifference(t *testing.T) {
+	assert.DeepEqual(t, []string{"alice"}, difference([]string{"alice", "bob"}, []string{"bob", "carol"}))
+	assert.DeepEqual(t, []string{}, difference([]string{"alice"}, []string{"alice"}))
+	assert.DeepEqual(t, []string{}, difference(nil, []string{"alice"}))
+}
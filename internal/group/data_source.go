@@ -0,0 +1,110 @@
+package group
+
+import (
+	"context"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &groupDataSource{}
+	_ datasource.DataSourceWithConfigure = &groupDataSource{}
+)
+
+func NewGroupDataSource() datasource.DataSource {
+	return &groupDataSource{}
+}
+
+type groupDataSource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (d *groupDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group"
+}
+
+func (d *groupDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Name of the group",
+				Required:    true,
+			},
+			"gid": schema.Int64Attribute{
+				Description: "Group id",
+				Computed:    true,
+			},
+			"members": schema.SetAttribute{
+				Description: "Users the group is a supplementary group of",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *groupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, d.providerData)
+
+	var state LinuxGroupModel
+
+	diags := req.Config.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Name.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Group name unknown",
+			"Group name unknown",
+		)
+		return
+	}
+
+	if state.Name.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Missing group name",
+			"Missing group name",
+		)
+		return
+	}
+
+	group, commonError := Get(linuxCtx, state.Name.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if group == nil {
+		resp.Diagnostics.AddError("Group not found", "Check group exists on server")
+		return
+	}
+
+	state = NewLinuxGroupModel(group)
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (d *groupDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	d.providerData = providerData
+}
@@ -0,0 +1,280 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &groupMembershipResource{}
+	_ resource.ResourceWithConfigure   = &groupMembershipResource{}
+	_ resource.ResourceWithImportState = &groupMembershipResource{}
+)
+
+func NewGroupMembershipResource() resource.Resource {
+	return &groupMembershipResource{}
+}
+
+type groupMembershipResource struct {
+	providerData *util.LinuxProviderData
+}
+
+type LinuxGroupMembershipResourceModel struct {
+	Group types.String `tfsdk:"group"`
+	Users types.Set    `tfsdk:"users"`
+}
+
+func (m *LinuxGroupMembershipResourceModel) users(ctx context.Context) ([]string, diag.Diagnostics) {
+	users := []string{}
+	diags := m.Users.ElementsAs(ctx, &users, false)
+	sort.Strings(users)
+	return users, diags
+}
+
+func (r *groupMembershipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group_membership"
+}
+
+func (r *groupMembershipResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Ensures users are members of a group, leaving other members alone. Several memberships can share a group, but they should not be combined with `members` of `linux_group`",
+		Attributes: map[string]schema.Attribute{
+			"group": schema.StringAttribute{
+				Description: "Name of the group",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"users": schema.SetAttribute{
+				Description: "Users that are made members of the group, and removed from it on destroy",
+				ElementType: types.StringType,
+				Required:    true,
+			},
+		},
+	}
+}
+
+func addMember(linuxCtx util.LinuxContext, group string, user string) *util.CommonError {
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("gpasswd", "-a", user, group), nil)
+	return commonError
+}
+
+func removeMember(linuxCtx util.LinuxContext, group string, user string) *util.CommonError {
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("gpasswd", "-d", user, group), nil)
+	return commonError
+}
+
+// apply adds the users to the group and removes the given ones, and returns the memberships found afterwards.
+func (r *groupMembershipResource) apply(linuxCtx util.LinuxContext, name string, add []string, remove []string) ([]string, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	group, commonError := Get(linuxCtx, name)
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+	if group == nil {
+		diags.AddAttributeError(path.Root("group"), "Group not found", "Group \""+name+"\" does not exist on the server")
+		return nil, diags
+	}
+
+	// Only missing members are added and present ones removed, since gpasswd -d fails for non-members.
+	for _, user := range difference(add, group.Members) {
+		if commonError := addMember(linuxCtx, name, user); commonError != nil {
+			return nil, commonError.Diagnostics
+		}
+	}
+	for _, user := range difference(remove, difference(remove, group.Members)) {
+		if commonError := removeMember(linuxCtx, name, user); commonError != nil {
+			return nil, commonError.Diagnostics
+		}
+	}
+
+	group, commonError = Get(linuxCtx, name)
+	if commonError != nil {
+		return nil, commonError.Diagnostics
+	}
+	if group == nil {
+		diags.AddAttributeError(path.Root("group"), "Group not found", "Group \""+name+"\" was removed while updating its members")
+		return nil, diags
+	}
+	return group.Members, diags
+}
+
+// managedUsers returns the users of the model that are members of the group.
+func managedUsers(users []string, members []string) types.Set {
+	return newMembersValue(difference(users, difference(users, members)))
+}
+
+func (r *groupMembershipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan LinuxGroupMembershipResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	users, diags := plan.users(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, diags := r.apply(linuxCtx, plan.Group.ValueString(), users, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.Users = managedUsers(users, members)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *groupMembershipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxGroupMembershipResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	group, commonError := Get(linuxCtx, state.Group.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if group == nil {
+		resp.State.RemoveResource(linuxCtx.Ctx)
+		return
+	}
+
+	users, diags := state.users(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Users removed from the group outside of Terraform are dropped, so that they are added again.
+	state.Users = managedUsers(users, group.Members)
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *groupMembershipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var plan LinuxGroupMembershipResourceModel
+	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state LinuxGroupMembershipResourceModel
+	diags = req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, diags := plan.users(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	stateUsers, diags := state.users(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, diags := r.apply(linuxCtx, plan.Group.ValueString(), users, difference(stateUsers, users))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.Users = managedUsers(users, members)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *groupMembershipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxGroupMembershipResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, diags := state.users(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	group, commonError := Get(linuxCtx, state.Group.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if group == nil {
+		return
+	}
+
+	_, diags = r.apply(linuxCtx, state.Group.ValueString(), nil, users)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *groupMembershipResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+// ImportState takes an id of the form group:user, managing the membership of that user in the group.
+func (r *groupMembershipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	group, user, found := strings.Cut(req.ID, ":")
+	if !found || group == "" || user == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			fmt.Sprintf("Expected an id of the form group:user, got \"%s\"", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("group"), group)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("users"), newMembersValue([]string{user}))...)
+}
@@ -0,0 +1,273 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &groupResource{}
+	_ resource.ResourceWithConfigure   = &groupResource{}
+	_ resource.ResourceWithImportState = &groupResource{}
+)
+
+func NewGroupResource() resource.Resource {
+	return &groupResource{}
+}
+
+type groupResource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (r *groupResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group"
+}
+
+func (r *groupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Name of the group",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"gid": schema.Int64Attribute{
+				Description: "Group id. Defaults to the next free gid",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"members": schema.SetAttribute{
+				Description: "Users the group is a supplementary group of. Every other member is removed, so it should not be combined with `linux_group_membership` on the same group",
+				ElementType: types.StringType,
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"system": schema.BoolAttribute{
+				Description: "Create a system group, with a gid from the system range. Only applies when the group is created, so changing it replaces the group",
+				Computed:    true,
+				Optional:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan LinuxGroupResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	name := plan.Name.ValueString()
+	if plan.Name.IsUnknown() || name == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Empty group name is not allowed",
+			"Please specify a group name",
+		)
+		return
+	}
+
+	argv := []string{"groupadd"}
+	if !plan.Gid.IsUnknown() && !plan.Gid.IsNull() {
+		argv = append(argv, "--gid", fmt.Sprintf("%d", plan.Gid.ValueInt64()))
+	}
+	if plan.System.ValueBool() {
+		argv = append(argv, "--system")
+	}
+	argv = append(argv, name)
+
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	members, ok, diags := plan.members(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if ok {
+		commonError = SetMembers(linuxCtx, name, members)
+		if commonError != nil {
+			resp.Diagnostics.Append(commonError.Diagnostics...)
+			return
+		}
+	}
+
+	group, commonError := Get(linuxCtx, name)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if group == nil {
+		resp.Diagnostics.AddError("Failed to create group", "Group not exists after creation request")
+		return
+	}
+
+	plan.applyLinuxGroup(group)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxGroupResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	group, commonError := Get(linuxCtx, state.Name.ValueString())
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if group == nil {
+		resp.State.RemoveResource(linuxCtx.Ctx)
+		return
+	}
+
+	state.applyLinuxGroup(group)
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var plan LinuxGroupResourceModel
+	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state LinuxGroupResourceModel
+	diags = req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	name := plan.Name.ValueString()
+
+	if !plan.Gid.IsUnknown() && !plan.Gid.IsNull() && !plan.Gid.Equal(state.Gid) {
+		_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("groupmod", "--gid", fmt.Sprintf("%d", plan.Gid.ValueInt64()), name), nil)
+		if commonError != nil {
+			resp.Diagnostics.Append(commonError.Diagnostics...)
+			return
+		}
+	}
+
+	members, ok, diags := plan.members(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if ok && !plan.Members.Equal(state.Members) {
+		commonError := SetMembers(linuxCtx, name, members)
+		if commonError != nil {
+			resp.Diagnostics.Append(commonError.Diagnostics...)
+			return
+		}
+	}
+
+	group, commonError := Get(linuxCtx, name)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if group == nil {
+		resp.Diagnostics.AddError("Failed to update group", "Group not exists after update request")
+		return
+	}
+
+	plan.applyLinuxGroup(group)
+	diags = resp.State.Set(linuxCtx.Ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
+
+	var state LinuxGroupResourceModel
+	diags := req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Name.IsUnknown() || state.Name.IsNull() || state.Name.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Empty group name is not allowed",
+			"Please specify a group name",
+		)
+		return
+	}
+
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("groupdel", state.Name.ValueString()), nil)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+}
+
+func (r *groupResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *groupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
+}
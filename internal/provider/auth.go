@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/melbahja/goph"
+	"golang.org/x/crypto/ssh"
+)
+
+// authOptions holds the authentication attributes shared by every SSH connection the provider opens.
+type authOptions struct {
+	Password             types.String
+	PrivateKey           types.String
+	PrivateKeyPassphrase types.String
+	Certificate          types.String
+	Agent                types.Bool
+}
+
+func stringValue(value types.String) string {
+	if value.IsNull() || value.IsUnknown() {
+		return ""
+	}
+	return value.ValueString()
+}
+
+// validate checks that values are known and that at least one usable method is configured.
+func (o authOptions) validate(root path.Path) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	for _, attribute := range []struct {
+		name  string
+		value types.String
+	}{
+		{"password", o.Password},
+		{"private_key", o.PrivateKey},
+		{"private_key_passphrase", o.PrivateKeyPassphrase},
+		{"certificate", o.Certificate},
+	} {
+		if attribute.value.IsUnknown() {
+			diags.AddAttributeError(
+				root.AtName(attribute.name),
+				"Unknown "+attribute.name,
+				"Authentication attributes must be known when the provider is configured",
+			)
+		}
+	}
+	if o.Agent.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName("agent"),
+			"Unknown agent",
+			"Authentication attributes must be known when the provider is configured",
+		)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	privateKey := stringValue(o.PrivateKey)
+	useAgent := o.Agent.ValueBool()
+
+	if stringValue(o.Password) == "" && privateKey == "" && !useAgent {
+		diags.AddAttributeError(
+			root,
+			"Missing authentication method",
+			"Please specify one of password, private_key or agent",
+		)
+	}
+	if stringValue(o.PrivateKeyPassphrase) != "" && privateKey == "" {
+		diags.AddAttributeError(
+			root.AtName("private_key_passphrase"),
+			"Passphrase without private key",
+			"private_key_passphrase requires private_key",
+		)
+	}
+	if stringValue(o.Certificate) != "" && privateKey == "" {
+		diags.AddAttributeError(
+			root.AtName("certificate"),
+			"Certificate without private key",
+			"certificate requires the matching private_key",
+		)
+	}
+	if useAgent && !goph.HasAgent() {
+		diags.AddAttributeError(
+			root.AtName("agent"),
+			"SSH agent not found",
+			"agent requires SSH_AUTH_SOCK to point to a running ssh-agent",
+		)
+	}
+
+	return diags
+}
+
+// auth builds the SSH authentication methods in the order they are offered to the server:
+// agent, private key (optionally as certificate) and password.
+func (o authOptions) auth(root path.Path) (goph.Auth, diag.Diagnostics) {
+	diags := o.validate(root)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	auth := goph.Auth{}
+
+	if o.Agent.ValueBool() {
+		agentAuth, err := goph.UseAgent()
+		if err != nil {
+			diags.AddAttributeError(root.AtName("agent"), "Failed to connect to SSH agent", err.Error())
+			return nil, diags
+		}
+		auth = append(auth, agentAuth...)
+	}
+
+	if privateKey := stringValue(o.PrivateKey); privateKey != "" {
+		signer, err := goph.GetSignerForRawKey([]byte(privateKey), stringValue(o.PrivateKeyPassphrase))
+		if err != nil {
+			if _, ok := err.(*ssh.PassphraseMissingError); ok {
+				diags.AddAttributeError(root.AtName("private_key_passphrase"), "Missing private key passphrase", "private_key is encrypted, please specify private_key_passphrase")
+			} else {
+				diags.AddAttributeError(root.AtName("private_key"), "Failed to parse private key", err.Error())
+			}
+			return nil, diags
+		}
+
+		if certificate := stringValue(o.Certificate); certificate != "" {
+			publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(certificate))
+			if err != nil {
+				diags.AddAttributeError(root.AtName("certificate"), "Failed to parse certificate", err.Error())
+				return nil, diags
+			}
+			cert, ok := publicKey.(*ssh.Certificate)
+			if !ok {
+				diags.AddAttributeError(root.AtName("certificate"), "Invalid certificate", "certificate should be an OpenSSH certificate such as the content of id_ed25519-cert.pub")
+				return nil, diags
+			}
+			signer, err = ssh.NewCertSigner(cert, signer)
+			if err != nil {
+				diags.AddAttributeError(root.AtName("certificate"), "Certificate does not match private key", err.Error())
+				return nil, diags
+			}
+		}
+
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if password := stringValue(o.Password); password != "" {
+		auth = append(auth, goph.KeyboardInteractive(password)...)
+	}
+
+	return auth, diags
+}
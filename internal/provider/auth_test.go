@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"gotest.tools/assert"
+)
+
+func generateTestKey(t *testing.T) (string, ssh.Signer) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	assert.NilError(t, err)
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	assert.NilError(t, err)
+
+	return string(pem.EncodeToMemory(block)), signer
+}
+
+func TestAuthMissingMethod(t *testing.T) {
+	_, diags := authOptions{}.auth(path.Empty())
+
+	assert.Assert(t, diags.HasError())
+	assert.Equal(t, "Missing authentication method", diags[0].Summary())
+}
+
+func TestAuthPassphraseWithoutPrivateKey(t *testing.T) {
+	_, diags := authOptions{
+		Password:             types.StringValue("secret"),
+		PrivateKeyPassphrase: types.StringValue("passphrase"),
+	}.auth(path.Empty())
+
+	assert.Assert(t, diags.HasError())
+	assert.Equal(t, "Passphrase without private key", diags[0].Summary())
+}
+
+func TestAuthCertificate(t *testing.T) {
+	privateKey, signer := generateTestKey(t)
+	_, caSigner := generateTestKey(t)
+
+	cert := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		CertType:        ssh.UserCert,
+		ValidPrincipals: []string{"root"},
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	assert.NilError(t, cert.SignCert(rand.Reader, caSigner))
+
+	auth, diags := authOptions{
+		PrivateKey:  types.StringValue(privateKey),
+		Certificate: types.StringValue(string(ssh.MarshalAuthorizedKey(cert))),
+	}.auth(path.Empty())
+
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	assert.Equal(t, 1, len(auth))
+}
+
+func TestAuthCertificateMismatch(t *testing.T) {
+	privateKey, _ := generateTestKey(t)
+	_, otherSigner := generateTestKey(t)
+
+	cert := &ssh.Certificate{
+		Key:         otherSigner.PublicKey(),
+		CertType:    ssh.UserCert,
+		ValidBefore: ssh.CertTimeInfinity,
+	}
+	assert.NilError(t, cert.SignCert(rand.Reader, otherSigner))
+
+	_, diags := authOptions{
+		PrivateKey:  types.StringValue(privateKey),
+		Certificate: types.StringValue(string(ssh.MarshalAuthorizedKey(cert))),
+	}.auth(path.Empty())
+
+	assert.Assert(t, diags.HasError())
+	assert.Equal(t, "Certificate does not match private key", diags[0].Summary())
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/melbahja/goph"
+	"golang.org/x/crypto/ssh"
+)
+
+type BastionModel struct {
+	Host                  types.String `tfsdk:"host"`
+	Username              types.String `tfsdk:"username"`
+	Port                  types.Int64  `tfsdk:"port"`
+	Password              types.String `tfsdk:"password"`
+	PrivateKey            types.String `tfsdk:"private_key"`
+	PrivateKeyPassphrase  types.String `tfsdk:"private_key_passphrase"`
+	Certificate           types.String `tfsdk:"certificate"`
+	Agent                 types.Bool   `tfsdk:"agent"`
+	HostKey               types.String `tfsdk:"host_key"`
+	KnownHostsFile        types.String `tfsdk:"known_hosts_file"`
+	StrictHostKeyChecking types.String `tfsdk:"strict_host_key_checking"`
+}
+
+func (m *BastionModel) authOptions() authOptions {
+	return authOptions{
+		Password:             m.Password,
+		PrivateKey:           m.PrivateKey,
+		PrivateKeyPassphrase: m.PrivateKeyPassphrase,
+		Certificate:          m.Certificate,
+		Agent:                m.Agent,
+	}
+}
+
+func (m *BastionModel) hostKeyOptions() hostKeyOptions {
+	return hostKeyOptions{
+		HostKey:               m.HostKey,
+		KnownHostsFile:        m.KnownHostsFile,
+		StrictHostKeyChecking: m.StrictHostKeyChecking,
+	}
+}
+
+// hop is a single SSH connection of the chain leading to the managed host.
+type hop struct {
+	root     path.Path
+	config   *goph.Config
+	verifier *hostKeyVerifier
+}
+
+func newHop(root path.Path, host types.String, username types.String, port uint, timeout time.Duration, auth authOptions, hostKey hostKeyOptions) (*hop, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if host.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName("host"),
+			"Host unknown",
+			"Host is unknown",
+		)
+	}
+
+	if username.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName("username"),
+			"Username unknown",
+			"Username is unknown",
+		)
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if stringValue(host) == "" {
+		diags.AddAttributeError(
+			root.AtName("host"),
+			"Empty host",
+			"Please specify host",
+		)
+	}
+
+	if stringValue(username) == "" {
+		diags.AddAttributeError(
+			root.AtName("username"),
+			"Empty username",
+			"Please specify username",
+		)
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	sshAuth, authDiags := auth.auth(root)
+	diags.Append(authDiags...)
+
+	verifier, verifierDiags := hostKey.verifier(root)
+	diags.Append(verifierDiags...)
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &hop{
+		root: root,
+		config: &goph.Config{
+			User:     stringValue(username),
+			Addr:     stringValue(host),
+			Port:     port,
+			Auth:     sshAuth,
+			Timeout:  timeout,
+			Callback: verifier.Callback,
+		},
+		verifier: verifier,
+	}, diags
+}
+
+func (h *hop) address() string {
+	return net.JoinHostPort(h.config.Addr, fmt.Sprint(h.config.Port))
+}
+
+func (h *hop) clientConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User:            h.config.User,
+		Auth:            h.config.Auth,
+		Timeout:         h.config.Timeout,
+		HostKeyCallback: h.config.Callback,
+	}
+}
+
+// connect opens the SSH connection of the hop, directly when through is nil
+// and otherwise tunneled through the connection of the previous hop.
+func (h *hop) connect(through *ssh.Client) (*ssh.Client, error) {
+	if through == nil {
+		return ssh.Dial("tcp", h.address(), h.clientConfig())
+	}
+
+	conn, err := through.Dial("tcp", h.address())
+	if err != nil {
+		return nil, err
+	}
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, h.address(), h.clientConfig())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+// dial connects every hop in order and returns the client of the last one.
+// The clients of the bastions are closed as soon as the connection of the last one ends,
+// so closing the returned client, on reconnect or shutdown, closes the whole chain.
+func dial(hops []*hop) (*goph.Client, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	var client *ssh.Client
+	opened := []*ssh.Client{}
+	closeOpened := func() {
+		for index := len(opened) - 1; index >= 0; index-- {
+			opened[index].Close()
+		}
+	}
+
+	for _, h := range hops {
+		next, err := h.connect(client)
+		if err != nil {
+			closeOpened()
+			if verifyErr := h.verifier.Err(); verifyErr != nil {
+				diags.AddAttributeError(h.root, "Host key verification failed", verifyErr.Error())
+				return nil, diags
+			}
+			diags.AddAttributeError(h.root, "Failed to create client", fmt.Sprintf("Failed to connect to %s: %v", h.address(), err))
+			return nil, diags
+		}
+
+		opened = append(opened, next)
+		client = next
+	}
+
+	go func() {
+		client.Wait()
+		closeOpened()
+	}()
+
+	return &goph.Client{
+		Client: client,
+		Config: hops[len(hops)-1].config,
+	}, diags
+}
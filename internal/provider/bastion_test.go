@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"terraform-provider-linux/internal/util/sshtest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"gotest.tools/assert"
+)
+
+func testBastionModel(server *sshtest.Server, hostKey ssh.PublicKey) BastionModel {
+	host, port := server.Addr()
+	return BastionModel{
+		Host:     types.StringValue(host),
+		Username: types.StringValue("jump"),
+		Port:     types.Int64Value(int64(port)),
+		Password: types.StringValue(sshtest.Password),
+		HostKey:  types.StringValue(string(ssh.MarshalAuthorizedKey(hostKey))),
+	}
+}
+
+// newTestSshExecutor connects to target through bastions, which pin the host keys of their servers.
+func newTestSshExecutor(t *testing.T, target *sshtest.Server, bastions ...BastionModel) (*sshUtil.Executor, diag.Diagnostics) {
+	host, port := target.Addr()
+	config := &LinuxProviderModel{
+		Host:     types.StringValue(host),
+		Username: types.StringValue("root"),
+		Port:     types.Int64Value(int64(port)),
+		Password: types.StringValue(sshtest.Password),
+		HostKey:  types.StringValue(string(ssh.MarshalAuthorizedKey(target.HostKey))),
+		Bastions: bastions,
+	}
+	connection, diags := config.connectionOptions()
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+
+	executor, diags := newSshExecutor(config, connection)
+	if executor != nil {
+		t.Cleanup(func() {
+			executor.Close()
+		})
+	}
+	return executor, diags
+}
+
+// waitForConnections waits until every server has the expected number of open connections.
+func waitForConnections(t *testing.T, expected int, servers ...*sshtest.Server) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, server := range servers {
+		for server.Connections() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d open connections, got %d", expected, server.Connections())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func assertHostname(t *testing.T, executor *sshUtil.Executor) {
+	t.Helper()
+
+	result, err := executor.Run(context.Background(), "hostname")
+	assert.NilError(t, err)
+	assert.Equal(t, "target\n", result.Stdout)
+}
+
+func TestDialOneHop(t *testing.T) {
+	bastion := sshtest.NewServer(t)
+	target := sshtest.NewServer(t).Handle("hostname", sshtest.Response{Stdout: "target\n"})
+
+	executor, diags := newTestSshExecutor(t, target, testBastionModel(bastion, bastion.HostKey))
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+
+	assertHostname(t, executor)
+	waitForConnections(t, 1, bastion, target)
+
+	assert.NilError(t, executor.Close())
+	waitForConnections(t, 0, bastion, target)
+}
+
+func TestDialTwoHops(t *testing.T) {
+	first := sshtest.NewServer(t)
+	second := sshtest.NewServer(t)
+	target := sshtest.NewServer(t).Handle("hostname", sshtest.Response{Stdout: "target\n"})
+
+	executor, diags := newTestSshExecutor(t, target, testBastionModel(first, first.HostKey), testBastionModel(second, second.HostKey))
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+
+	assertHostname(t, executor)
+	waitForConnections(t, 1, first, second, target)
+
+	assert.NilError(t, executor.Close())
+	waitForConnections(t, 0, first, second, target)
+}
+
+func TestDialRedial(t *testing.T) {
+	bastion := sshtest.NewServer(t)
+	target := sshtest.NewServer(t).Handle("hostname", sshtest.Response{Stdout: "target\n"})
+
+	executor, diags := newTestSshExecutor(t, target, testBastionModel(bastion, bastion.HostKey))
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	assertHostname(t, executor)
+
+	// Losing the target closes the connection to the bastion as well
+	target.Disconnect()
+	waitForConnections(t, 0, bastion, target)
+
+	_, err := executor.Run(context.Background(), "hostname")
+	var transportError *util.TransportError
+	assert.Assert(t, errors.As(err, &transportError), "%v", err)
+
+	assertHostname(t, executor)
+	waitForConnections(t, 1, bastion, target)
+}
+
+func TestDialHostKeyRejectedOnHop(t *testing.T) {
+	first := sshtest.NewServer(t)
+	second := sshtest.NewServer(t)
+	target := sshtest.NewServer(t)
+
+	executor, diags := newTestSshExecutor(t, target, testBastionModel(first, first.HostKey), testBastionModel(second, target.HostKey))
+
+	assert.Assert(t, executor == nil)
+	assert.Equal(t, 1, diags.ErrorsCount(), "%v", diags)
+	assert.Equal(t, "Host key verification failed", diags.Errors()[0].Summary())
+	withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath)
+	assert.Assert(t, ok)
+	assert.Assert(t, withPath.Path().Equal(path.Root("bastion").AtListIndex(1)), "%v", withPath.Path())
+
+	// The hop that was already open is closed, and the target is never reached
+	waitForConnections(t, 0, first, second, target)
+}
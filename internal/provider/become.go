@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"fmt"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	becomeMethodSudo = "sudo"
+	becomeMethodDoas = "doas"
+	becomeMethodSu   = "su"
+)
+
+type BecomeModel struct {
+	Method         types.String `tfsdk:"method"`
+	BecomeUser     types.String `tfsdk:"become_user"`
+	BecomePassword types.String `tfsdk:"become_password"`
+}
+
+// become validates the block and returns how commands are elevated, or nil when the block is absent.
+func (m *BecomeModel) become(root path.Path) (*util.Become, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+	if m == nil {
+		return nil, diags
+	}
+
+	for _, attribute := range []struct {
+		name  string
+		value types.String
+	}{
+		{"method", m.Method},
+		{"become_user", m.BecomeUser},
+		{"become_password", m.BecomePassword},
+	} {
+		if attribute.value.IsUnknown() {
+			diags.AddAttributeError(
+				root.AtName(attribute.name),
+				"Unknown "+attribute.name,
+				"Become attributes must be known when the provider is configured",
+			)
+		}
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	become := &util.Become{
+		Method:   stringValue(m.Method),
+		User:     stringValue(m.BecomeUser),
+		Password: stringValue(m.BecomePassword),
+	}
+	if become.Method == "" {
+		become.Method = becomeMethodSudo
+	}
+	if become.User == "" {
+		become.User = "root"
+	}
+
+	switch become.Method {
+	case becomeMethodSudo:
+	case becomeMethodDoas, becomeMethodSu:
+		// doas and su read the password from a terminal rather than stdin.
+		if become.Password != "" {
+			diags.AddAttributeError(
+				root.AtName("become_password"),
+				"Unsupported become_password",
+				fmt.Sprintf("become_password is only supported with \"sudo\". Configure %s to run without a password for the login user instead", become.Method),
+			)
+		}
+	default:
+		diags.AddAttributeError(
+			root.AtName("method"),
+			"Invalid method",
+			fmt.Sprintf("Expected one of \"sudo\", \"doas\" or \"su\", got \"%s\"", become.Method),
+		)
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return become, diags
+}
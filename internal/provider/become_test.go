@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"terraform-provider-linux/internal/util"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gotest.tools/assert"
+)
+
+func TestBecomeAbsent(t *testing.T) {
+	var model *BecomeModel
+
+	become, diags := model.become(path.Root("become"))
+
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	assert.Assert(t, become == nil)
+}
+
+func TestBecomeDefaults(t *testing.T) {
+	model := &BecomeModel{
+		Method:         types.StringNull(),
+		BecomeUser:     types.StringNull(),
+		BecomePassword: types.StringNull(),
+	}
+
+	become, diags := model.become(path.Root("become"))
+
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	assert.DeepEqual(t, &util.Become{Method: "sudo", User: "root"}, become)
+}
+
+func TestBecomePasswordRequiresSudo(t *testing.T) {
+	model := &BecomeModel{
+		Method:         types.StringValue("doas"),
+		BecomeUser:     types.StringNull(),
+		BecomePassword: types.StringValue("secret"),
+	}
+
+	_, diags := model.become(path.Root("become"))
+
+	assert.Equal(t, 1, diags.ErrorsCount())
+}
+
+func TestBecomeInvalidMethod(t *testing.T) {
+	model := &BecomeModel{
+		Method:         types.StringValue("pbrun"),
+		BecomeUser:     types.StringNull(),
+		BecomePassword: types.StringNull(),
+	}
+
+	_, diags := model.become(path.Root("become"))
+
+	assert.Equal(t, 1, diags.ErrorsCount())
+}
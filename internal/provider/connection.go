@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"fmt"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/melbahja/goph"
+)
+
+const (
+	connectionSsh   = "ssh"
+	connectionLocal = "local"
+)
+
+// connectionOptions holds the transport settings of the connection.
+type connectionOptions struct {
+	Type              string
+	Port              uint
+	ConnectTimeout    time.Duration
+	CommandTimeout    time.Duration
+	KeepaliveInterval time.Duration
+	Retry             util.RetryPolicy
+	MaxSessions       int
+}
+
+const (
+	defaultMaxRetries     = 2
+	defaultRetryBaseDelay = 2 * time.Second
+)
+
+func parseDuration(root path.Path, name string, value types.String, defaultValue time.Duration) (time.Duration, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if value.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName(name),
+			"Unknown "+name,
+			"Connection attributes must be known when the provider is configured",
+		)
+		return 0, diags
+	}
+	if value.IsNull() || value.ValueString() == "" {
+		return defaultValue, diags
+	}
+
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil || duration < 0 {
+		diags.AddAttributeError(
+			root.AtName(name),
+			"Invalid "+name,
+			fmt.Sprintf("Expected a non-negative duration such as \"30s\" or \"5m\", got \"%s\"", value.ValueString()),
+		)
+		return 0, diags
+	}
+
+	return duration, diags
+}
+
+func parsePort(root path.Path, value types.Int64) (uint, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if value.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName("port"),
+			"Unknown port",
+			"Connection attributes must be known when the provider is configured",
+		)
+		return 0, diags
+	}
+	if value.IsNull() {
+		return 22, diags
+	}
+
+	port := value.ValueInt64()
+	if port < 1 || port > 65535 {
+		diags.AddAttributeError(
+			root.AtName("port"),
+			"Invalid port",
+			fmt.Sprintf("Expected a port between 1 and 65535, got %d", port),
+		)
+		return 0, diags
+	}
+
+	return uint(port), diags
+}
+
+func parseCount(root path.Path, name string, value types.Int64, defaultValue int, min int64, max int64) (int, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if value.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName(name),
+			"Unknown "+name,
+			"Connection attributes must be known when the provider is configured",
+		)
+		return 0, diags
+	}
+	if value.IsNull() {
+		return defaultValue, diags
+	}
+
+	count := value.ValueInt64()
+	if count < min || count > max {
+		diags.AddAttributeError(
+			root.AtName(name),
+			"Invalid "+name,
+			fmt.Sprintf("Expected a number between %d and %d, got %d", min, max, count),
+		)
+		return 0, diags
+	}
+
+	return int(count), diags
+}
+
+func parseConnection(root path.Path, value types.String) (string, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if value.IsUnknown() {
+		diags.AddAttributeError(
+			root.AtName("connection"),
+			"Unknown connection",
+			"Connection attributes must be known when the provider is configured",
+		)
+		return "", diags
+	}
+	if value.IsNull() || value.ValueString() == "" {
+		return connectionSsh, diags
+	}
+
+	switch value.ValueString() {
+	case connectionSsh, connectionLocal:
+	default:
+		diags.AddAttributeError(
+			root.AtName("connection"),
+			"Invalid connection",
+			fmt.Sprintf("Expected \"ssh\" or \"local\", got \"%s\"", value.ValueString()),
+		)
+	}
+
+	return value.ValueString(), diags
+}
+
+func (m *LinuxProviderModel) connectionOptions() (connectionOptions, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+	root := path.Empty()
+
+	connectionType, connectionDiags := parseConnection(root, m.Connection)
+	diags.Append(connectionDiags...)
+
+	port, portDiags := parsePort(root, m.Port)
+	diags.Append(portDiags...)
+
+	connectTimeout, durationDiags := parseDuration(root, "connect_timeout", m.ConnectTimeout, goph.DefaultTimeout)
+	diags.Append(durationDiags...)
+
+	commandTimeout, durationDiags := parseDuration(root, "command_timeout", m.CommandTimeout, 0)
+	diags.Append(durationDiags...)
+
+	keepaliveInterval, durationDiags := parseDuration(root, "keepalive_interval", m.KeepaliveInterval, 0)
+	diags.Append(durationDiags...)
+
+	maxRetries, countDiags := parseCount(root, "max_retries", m.MaxRetries, defaultMaxRetries, 0, 10)
+	diags.Append(countDiags...)
+
+	retryBaseDelay, durationDiags := parseDuration(root, "retry_base_delay", m.RetryBaseDelay, defaultRetryBaseDelay)
+	diags.Append(durationDiags...)
+
+	maxSessions, countDiags := parseCount(root, "max_sessions", m.MaxSessions, util.DefaultMaxSessions, 1, 1000)
+	diags.Append(countDiags...)
+
+	return connectionOptions{
+		Type:              connectionType,
+		Port:              port,
+		ConnectTimeout:    connectTimeout,
+		CommandTimeout:    commandTimeout,
+		KeepaliveInterval: keepaliveInterval,
+		Retry: util.RetryPolicy{
+			MaxRetries: maxRetries,
+			BaseDelay:  retryBaseDelay,
+		},
+		MaxSessions: maxSessions,
+	}, diags
+}
+
+// newSshExecutor connects to the host through the configured bastions.
+// The connection is redialed with the same settings whenever it breaks.
+func newSshExecutor(config *LinuxProviderModel, connection connectionOptions) (*sshUtil.Executor, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	hops := []*hop{}
+	for index, bastion := range config.Bastions {
+		root := path.Root("bastion").AtListIndex(index)
+		bastionPort, portDiags := parsePort(root, bastion.Port)
+		diags.Append(portDiags...)
+
+		bastionHop, hopDiags := newHop(root, bastion.Host, bastion.Username, bastionPort, connection.ConnectTimeout, bastion.authOptions(), bastion.hostKeyOptions())
+		diags.Append(hopDiags...)
+		hops = append(hops, bastionHop)
+	}
+
+	targetHop, hopDiags := newHop(path.Empty(), config.Host, config.Username, connection.Port, connection.ConnectTimeout, config.authOptions(), config.hostKeyOptions())
+	diags.Append(hopDiags...)
+	hops = append(hops, targetHop)
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	sshClient, dialDiags := dial(hops)
+	diags.Append(dialDiags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var sessions *util.SessionManager
+	startKeepalive := func(sshClient *goph.Client) {
+		sshUtil.StartKeepalive(sshClient, connection.KeepaliveInterval, func() {
+			sessions.MarkBroken(sshClient)
+		})
+	}
+	reconnect := func() (*goph.Client, error) {
+		sshClient, diags := dial(hops)
+		if diags.HasError() {
+			return nil, fmt.Errorf("%s: %s", diags.Errors()[0].Summary(), diags.Errors()[0].Detail())
+		}
+		startKeepalive(sshClient)
+		return sshClient, nil
+	}
+	sessions = util.NewSessionManager(sshClient, reconnect, connection.MaxSessions)
+	startKeepalive(sshClient)
+
+	return sshUtil.NewExecutor(sessions), diags
+}
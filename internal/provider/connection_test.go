@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"terraform-provider-linux/internal/util"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/melbahja/goph"
+	"gotest.tools/assert"
+)
+
+func TestConnectionOptionsDefaults(t *testing.T) {
+	model := &LinuxProviderModel{}
+
+	connection, diags := model.connectionOptions()
+
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	assert.DeepEqual(t, connectionOptions{
+		Type:           "ssh",
+		Port:           22,
+		ConnectTimeout: goph.DefaultTimeout,
+		Retry: util.RetryPolicy{
+			MaxRetries: 2,
+			BaseDelay:  2 * time.Second,
+		},
+		MaxSessions: 10,
+	}, connection)
+}
+
+func TestConnectionOptions(t *testing.T) {
+	model := &LinuxProviderModel{
+		Connection:        types.StringValue("local"),
+		Port:              types.Int64Value(2222),
+		ConnectTimeout:    types.StringValue("5s"),
+		CommandTimeout:    types.StringValue("10m"),
+		KeepaliveInterval: types.StringValue("30s"),
+		MaxRetries:        types.Int64Value(0),
+		RetryBaseDelay:    types.StringValue("500ms"),
+		MaxSessions:       types.Int64Value(4),
+	}
+
+	connection, diags := model.connectionOptions()
+
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	assert.DeepEqual(t, connectionOptions{
+		Type:              "local",
+		Port:              2222,
+		ConnectTimeout:    5 * time.Second,
+		CommandTimeout:    10 * time.Minute,
+		KeepaliveInterval: 30 * time.Second,
+		Retry: util.RetryPolicy{
+			MaxRetries: 0,
+			BaseDelay:  500 * time.Millisecond,
+		},
+		MaxSessions: 4,
+	}, connection)
+}
+
+func TestConnectionOptionsInvalid(t *testing.T) {
+	model := &LinuxProviderModel{
+		Port:           types.Int64Value(70000),
+		CommandTimeout: types.StringValue("soon"),
+		MaxRetries:     types.Int64Value(-1),
+		Connection:     types.StringValue("telnet"),
+	}
+
+	_, diags := model.connectionOptions()
+
+	assert.Equal(t, 4, diags.ErrorsCount())
+}
@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func testAccDirectoryResourceConfig(host *testAccTarget, mode string, recursive bool, purge bool, keep string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_directory" "test" {
+  path      = "/srv/acc"
+  mode      = %q
+  owner     = "daemon"
+  group     = "daemon"
+  recursive = %t
+  purge     = %t
+  keep      = [%s]
+}
+`, mode, recursive, purge, keep)
+}
+
+// testAccCheckDirectory verifies mode and owner of remotePath on the host.
+func testAccCheckDirectory(host *testAccTarget, remotePath string, mode os.FileMode, uid int64) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		stat, err := host.stat(remotePath)
+		if err != nil {
+			return err
+		}
+		if stat == nil || stat.Type != "directory" {
+			return fmt.Errorf("expected %s to be a directory on the host", remotePath)
+		}
+		if stat.Mode != mode {
+			return fmt.Errorf("expected mode %o for %s, got %o", mode, remotePath, stat.Mode)
+		}
+		if stat.Uid != uid {
+			return fmt.Errorf("expected owner %d for %s, got %d", uid, remotePath, stat.Uid)
+		}
+		return nil
+	}
+}
+
+// testAccCheckExists fails when remotePath does not exist on the host.
+func testAccCheckExists(host *testAccTarget, remotePath string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		stat, err := host.stat(remotePath)
+		if err != nil {
+			return err
+		}
+		if stat == nil {
+			return fmt.Errorf("expected %s to exist on the host", remotePath)
+		}
+		return nil
+	}
+}
+
+func testAccCheckDirectoryDestroy(host *testAccTarget) resource.TestCheckFunc {
+	return testAccCheckNoResources("linux_directory", func(attributes map[string]string) error {
+		return testAccCheckNotExists(host, attributes["path"])
+	})
+}
+
+func TestAccDirectoryResource(t *testing.T) {
+	host := testAccHost(t)
+	host.removeOnCleanup("/srv/acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDirectoryDestroy(host),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccDirectoryResourceConfig(host, "0750", false, false, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory.test", "path", "/srv/acc"),
+					resource.TestCheckResourceAttr("linux_directory.test", "mode", "0750"),
+					resource.TestCheckResourceAttr("linux_directory.test", "owner", "daemon"),
+					resource.TestCheckResourceAttr("linux_directory.test", "recursive", "false"),
+					resource.TestCheckResourceAttr("linux_directory.test", "purge", "false"),
+					testAccCheckDirectory(host, "/srv/acc", 0750, 1),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "linux_directory.test",
+				ImportState:                          true,
+				ImportStateId:                        "/srv/acc",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "path",
+			},
+			// Drift testing
+			{
+				PreConfig: func() {
+					host.mustRun("chmod", "0700", "--", "/srv/acc")
+				},
+				Config:             testAccDirectoryResourceConfig(host, "0750", false, false, ""),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Update and Read testing, applying ownership to children created outside of Terraform
+			{
+				PreConfig: func() {
+					host.writeFile("/srv/acc/app.conf", "managed\n", 0644)
+				},
+				Config: testAccDirectoryResourceConfig(host, "0755", true, false, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory.test", "mode", "0755"),
+					resource.TestCheckResourceAttr("linux_directory.test", "recursive", "true"),
+					testAccCheckDirectory(host, "/srv/acc", 0755, 1),
+					testAccCheckFile(host, "/srv/acc/app.conf", "managed\n", 0644, 1),
+				),
+			},
+			{
+				PreConfig: func() {
+					host.mustRun("chown", "root", "--", "/srv/acc/app.conf")
+				},
+				Config:             testAccDirectoryResourceConfig(host, "0755", true, false, ""),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Purge removes unmanaged children, including names that contain a newline, and keeps the listed ones
+			{
+				PreConfig: func() {
+					host.writeFile("/srv/acc/stale.conf", "stale\n", 0644)
+					host.writeFile("/srv/acc/multi\nline", "stale\n", 0644)
+					host.mustRun("mkdir", "--", "/srv/acc/.cache")
+				},
+				Config: testAccDirectoryResourceConfig(host, "0755", true, true, `"app.conf"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory.test", "purge", "true"),
+					testAccCheckFile(host, "/srv/acc/app.conf", "managed\n", 0644, 1),
+					func(*terraform.State) error {
+						for _, remotePath := range []string{"/srv/acc/stale.conf", "/srv/acc/multi\nline", "/srv/acc/.cache"} {
+							if err := testAccCheckNotExists(host, remotePath); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				),
+			},
+			{
+				PreConfig: func() {
+					host.writeFile("/srv/acc/stale.conf", "stale\n", 0644)
+				},
+				Config:             testAccDirectoryResourceConfig(host, "0755", true, true, `"app.conf"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// A kept child with a newline in its name is a single entry, so the plan is empty
+			{
+				PreConfig: func() {
+					host.mustRun("rm", "--", "/srv/acc/stale.conf")
+					host.writeFile("/srv/acc/multi\nline", "kept\n", 0644)
+				},
+				Config: testAccDirectoryResourceConfig(host, "0755", true, true, `"app.conf", "multi\nline"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_directory.test", "keep.#", "2"),
+					testAccCheckExists(host, "/srv/acc/multi\nline"),
+				),
+			},
+			{
+				Config:   testAccDirectoryResourceConfig(host, "0755", true, true, `"app.conf", "multi\nline"`),
+				PlanOnly: true,
+			},
+			// Delete testing automatically occurs in TestCase, removing the directory with its content
+		},
+	})
+}
+
+func TestAccDirectoryResourceNotEmpty(t *testing.T) {
+	host := testAccHost(t)
+	host.removeOnCleanup("/srv/acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDirectoryDestroy(host),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDirectoryResourceConfig(host, "0755", false, false, ""),
+			},
+			// Without purge, a directory holding unmanaged data is not removed
+			{
+				PreConfig: func() {
+					host.writeFile("/srv/acc/data", "precious\n", 0644)
+				},
+				Config:      testAccProviderConfig(host),
+				ExpectError: regexp.MustCompile("Directory not empty"),
+			},
+			{
+				Config: testAccDirectoryResourceConfig(host, "0755", false, false, ""),
+				Check:  testAccCheckFile(host, "/srv/acc/data", "precious\n", 0644, 0),
+			},
+			// Once it is empty, the directory is removed
+			{
+				PreConfig: func() {
+					host.mustRun("rm", "--", "/srv/acc/data")
+				},
+				Config: testAccProviderConfig(host),
+				Check: func(*terraform.State) error {
+					return testAccCheckNotExists(host, "/srv/acc")
+				},
+			},
+		},
+	})
+}
+
+func TestAccDirectoryResourceExisting(t *testing.T) {
+	host := testAccHost(t)
+	host.removeOnCleanup("/srv/acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDirectoryDestroy(host),
+		Steps: []resource.TestStep{
+			// An existing directory is not adopted, so purge cannot remove data that Terraform never managed
+			{
+				PreConfig: func() {
+					host.mustRun("mkdir", "--", "/srv/acc")
+					host.writeFile("/srv/acc/data", "precious\n", 0644)
+				},
+				Config:      testAccDirectoryResourceConfig(host, "0755", true, true, ""),
+				ExpectError: regexp.MustCompile("already exists on the server"),
+			},
+			{
+				Config: testAccProviderConfig(host),
+				Check:  testAccCheckFile(host, "/srv/acc/data", "precious\n", 0644, 0),
+			},
+		},
+	})
+}
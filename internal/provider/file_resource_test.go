@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func testAccFileResourceConfig(host *testAccTarget, content string, mode string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "owner" {
+  username = "bob"
+  uid      = 1800
+}
+
+resource "linux_file" "test" {
+  path    = "/motd"
+  content = %q
+  mode    = %q
+  owner   = linux_user.owner.username
+}
+`, content, mode)
+}
+
+// testAccCheckFile verifies content, mode and owner of remotePath on the host.
+func testAccCheckFile(host *testAccTarget, remotePath string, content string, mode os.FileMode, uid int64) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		actual, err := host.readFile(remotePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s on the host: %w", remotePath, err)
+		}
+		if actual != content {
+			return fmt.Errorf("expected content %q for %s, got %q", content, remotePath, actual)
+		}
+
+		stat, err := host.stat(remotePath)
+		if err != nil {
+			return err
+		}
+		if stat.Mode != mode {
+			return fmt.Errorf("expected mode %o for %s, got %o", mode, remotePath, stat.Mode)
+		}
+		if stat.Uid != uid {
+			return fmt.Errorf("expected owner %d for %s, got %d", uid, remotePath, stat.Uid)
+		}
+		return nil
+	}
+}
+
+func testAccCheckFileDestroy(host *testAccTarget) resource.TestCheckFunc {
+	return testAccCheckNoResources("linux_file", func(attributes map[string]string) error {
+		return testAccCheckNotExists(host, attributes["path"])
+	})
+}
+
+func TestAccFileResource(t *testing.T) {
+	host := testAccHost(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckFileDestroy(host),
+			testAccCheckUserDestroy(host),
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccFileResourceConfig(host, "hello\n", "0640"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.test", "path", "/motd"),
+					resource.TestCheckResourceAttr("linux_file.test", "mode", "0640"),
+					resource.TestCheckResourceAttr("linux_file.test", "owner", "bob"),
+					resource.TestCheckResourceAttr("linux_file.test", "sha256", "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"),
+					testAccCheckFile(host, "/motd", "hello\n", 0640, 1800),
+				),
+			},
+			// ImportState testing. Content is not read back from the host.
+			{
+				ResourceName:                         "linux_file.test",
+				ImportState:                          true,
+				ImportStateId:                        "/motd",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "path",
+				ImportStateVerifyIgnore:              []string{"content"},
+			},
+			// Drift testing
+			{
+				PreConfig: func() {
+					host.writeFile("/motd", "changed\n", 0640)
+				},
+				Config:             testAccFileResourceConfig(host, "hello\n", "0640"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Update and Read testing, which also reverts the drift
+			{
+				Config: testAccFileResourceConfig(host, "hello world\n", "0600"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.test", "mode", "0600"),
+					testAccCheckFile(host, "/motd", "hello world\n", 0600, 1800),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccFileResourceUnsetConfig(host *testAccTarget, content string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_file" "test" {
+  path    = "/issue"
+  content = %q
+}
+`, content)
+}
+
+func TestAccFileResourceUnsetAttributes(t *testing.T) {
+	host := testAccHost(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckFileDestroy(host),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFileResourceUnsetConfig(host, "hello\n"),
+			},
+			// Mode and ownership changed outside of Terraform are kept when the content is rewritten
+			{
+				PreConfig: func() {
+					host.mustRun("chmod", "0604", "--", "/issue")
+				},
+				Config: testAccFileResourceUnsetConfig(host, "hello world\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.test", "mode", "0604"),
+					testAccCheckFile(host, "/issue", "hello world\n", 0604, 0),
+				),
+			},
+			// A directory replacing the file is not overwritten
+			{
+				PreConfig: func() {
+					host.mustRun("rm", "--", "/issue")
+					host.mustRun("mkdir", "--", "/issue")
+				},
+				Config:      testAccFileResourceUnsetConfig(host, "hello world\n"),
+				ExpectError: regexp.MustCompile("Path is not a regular file"),
+			},
+			{
+				PreConfig: func() {
+					host.mustRun("rmdir", "--", "/issue")
+				},
+				Config: testAccFileResourceUnsetConfig(host, "hello world\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_file.test", "mode", "0600"),
+					testAccCheckFile(host, "/issue", "hello world\n", 0600, 0),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func testAccGroupMembershipResourceConfig(host *testAccTarget, users string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "alice" {
+  username = "alice"
+}
+
+resource "linux_user" "bob" {
+  username = "bob"
+}
+
+resource "linux_group" "test" {
+  name = "developers"
+  gid  = 3000
+}
+
+resource "linux_group_membership" "test" {
+  group = linux_group.test.name
+  users = [%s]
+}
+`, users)
+}
+
+func testAccCheckGroupMembershipDestroy(host *testAccTarget) resource.TestCheckFunc {
+	return testAccCheckNoResources("linux_group_membership", func(attributes map[string]string) error {
+		group, err := host.group(attributes["group"])
+		if err != nil {
+			return err
+		}
+		if group != nil && len(group.Members) != 0 {
+			return fmt.Errorf("group %s still has members %v on the host", group.Name, group.Members)
+		}
+		return nil
+	})
+}
+
+func TestAccGroupMembershipResource(t *testing.T) {
+	host := testAccHost(t)
+	// Carol is managed outside of this configuration and should keep her membership.
+	host.addUser("carol", 2100)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckGroupMembershipDestroy(host),
+			testAccCheckGroupDestroy(host),
+			testAccCheckUserDestroy(host),
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccGroupMembershipResourceConfig(host, "linux_user.alice.username"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_group_membership.test", "group", "developers"),
+					resource.TestCheckTypeSetElemAttr("linux_group_membership.test", "users.*", "alice"),
+					testAccCheckGroup(host, "developers", 3000, "alice"),
+				),
+			},
+			// Members added outside of Terraform are left alone
+			{
+				PreConfig: func() {
+					host.setMembers("developers", "alice", "carol")
+				},
+				Config:   testAccGroupMembershipResourceConfig(host, "linux_user.alice.username"),
+				PlanOnly: true,
+			},
+			// Drift testing
+			{
+				PreConfig: func() {
+					host.setMembers("developers", "carol")
+				},
+				Config:             testAccGroupMembershipResourceConfig(host, "linux_user.alice.username"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Update and Read testing, which also reverts the drift
+			{
+				Config: testAccGroupMembershipResourceConfig(host, "linux_user.bob.username"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("linux_group_membership.test", "users.*", "bob"),
+					testAccCheckGroup(host, "developers", 3000, "bob", "carol"),
+				),
+			},
+			// ImportState testing, with an id of the form group:user
+			{
+				ResourceName:                         "linux_group_membership.test",
+				ImportState:                          true,
+				ImportStateId:                        "developers:bob",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "group",
+			},
+			{
+				ResourceName:  "linux_group_membership.test",
+				ImportState:   true,
+				ImportStateId: "developers",
+				ExpectError:   regexp.MustCompile("Expected an id of the form group:user"),
+			},
+			// Removing the membership keeps the other members
+			{
+				Config: testAccGroupMembershipResourceConfig(host, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_group_membership.test", "users.#", "0"),
+					testAccCheckGroup(host, "developers", 3000, "carol"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func testAccGroupResourceConfig(host *testAccTarget, gid int64, members string, system bool) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "alice" {
+  username = "alice"
+}
+
+resource "linux_user" "bob" {
+  username = "bob"
+}
+
+resource "linux_group" "test" {
+  name    = "developers"
+  gid     = %d
+  members = [%s]
+  system  = %t
+}
+
+data "linux_group" "test" {
+  name = linux_group.test.name
+}
+`, gid, members, system)
+}
+
+// testAccCheckGroup verifies the group on the host.
+func testAccCheckGroup(host *testAccTarget, name string, gid int64, members ...string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		group, err := host.group(name)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return fmt.Errorf("group %s does not exist on the host", name)
+		}
+		if group.Gid != gid {
+			return fmt.Errorf("expected gid %d for group %s, got %d", gid, name, group.Gid)
+		}
+		sort.Strings(members)
+		if strings.Join(group.Members, ",") != strings.Join(members, ",") {
+			return fmt.Errorf("expected members %v for group %s, got %v", members, name, group.Members)
+		}
+		return nil
+	}
+}
+
+func testAccCheckGroupDestroy(host *testAccTarget) resource.TestCheckFunc {
+	return testAccCheckNoResources("linux_group", func(attributes map[string]string) error {
+		group, err := host.group(attributes["name"])
+		if err != nil {
+			return err
+		}
+		if group != nil {
+			return fmt.Errorf("group %s still exists on the host", group.Name)
+		}
+		return nil
+	})
+}
+
+func TestAccGroupResource(t *testing.T) {
+	host := testAccHost(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckGroupDestroy(host),
+			testAccCheckUserDestroy(host),
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccGroupResourceConfig(host, 3000, "linux_user.alice.username", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_group.test", "name", "developers"),
+					resource.TestCheckResourceAttr("linux_group.test", "gid", "3000"),
+					resource.TestCheckResourceAttr("linux_group.test", "system", "false"),
+					resource.TestCheckTypeSetElemAttr("linux_group.test", "members.*", "alice"),
+					resource.TestCheckResourceAttr("data.linux_group.test", "gid", "3000"),
+					resource.TestCheckTypeSetElemAttr("data.linux_group.test", "members.*", "alice"),
+					testAccCheckGroup(host, "developers", 3000, "alice"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "linux_group.test",
+				ImportState:                          true,
+				ImportStateId:                        "developers",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			// Drift testing
+			{
+				PreConfig: func() {
+					host.setMembers("developers", "alice", "bob")
+				},
+				Config:             testAccGroupResourceConfig(host, 3000, "linux_user.alice.username", false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Update and Read testing, which also reverts the drift
+			{
+				Config: testAccGroupResourceConfig(host, 3100, "linux_user.bob.username", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_group.test", "gid", "3100"),
+					resource.TestCheckResourceAttr("data.linux_group.test", "gid", "3100"),
+					testAccCheckGroup(host, "developers", 3100, "bob"),
+				),
+			},
+			// system only applies at creation, so changing it replaces the group instead of a no-op update
+			{
+				Config: testAccGroupResourceConfig(host, 3100, "linux_user.bob.username", true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("linux_group.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_group.test", "system", "true"),
+					testAccCheckGroup(host, "developers", 3100, "bob"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/melbahja/goph"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	strictHostKeyCheckingYes       = "yes"
+	strictHostKeyCheckingNo        = "no"
+	strictHostKeyCheckingAcceptNew = "accept-new"
+)
+
+// hostKeyOptions holds the host key verification attributes of an SSH connection.
+type hostKeyOptions struct {
+	HostKey               types.String
+	KnownHostsFile        types.String
+	StrictHostKeyChecking types.String
+}
+
+func (o hostKeyOptions) strictHostKeyChecking() string {
+	if value := stringValue(o.StrictHostKeyChecking); value != "" {
+		return value
+	}
+	return strictHostKeyCheckingYes
+}
+
+func (o hostKeyOptions) knownHostsFile() (string, error) {
+	if value := stringValue(o.KnownHostsFile); value != "" {
+		return value, nil
+	}
+	return goph.DefaultKnownHostsPath()
+}
+
+func (o hostKeyOptions) validate(root path.Path) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	for _, attribute := range []struct {
+		name  string
+		value types.String
+	}{
+		{"host_key", o.HostKey},
+		{"known_hosts_file", o.KnownHostsFile},
+		{"strict_host_key_checking", o.StrictHostKeyChecking},
+	} {
+		if attribute.value.IsUnknown() {
+			diags.AddAttributeError(
+				root.AtName(attribute.name),
+				"Unknown "+attribute.name,
+				"Host key attributes must be known when the provider is configured",
+			)
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	switch o.strictHostKeyChecking() {
+	case strictHostKeyCheckingYes, strictHostKeyCheckingNo, strictHostKeyCheckingAcceptNew:
+	default:
+		diags.AddAttributeError(
+			root.AtName("strict_host_key_checking"),
+			"Invalid strict_host_key_checking",
+			fmt.Sprintf("Expected one of \"yes\", \"no\" or \"accept-new\", got \"%s\"", o.strictHostKeyChecking()),
+		)
+	}
+
+	if hostKey := stringValue(o.HostKey); hostKey != "" && !strings.HasPrefix(hostKey, "SHA256:") {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey)); err != nil {
+			diags.AddAttributeError(
+				root.AtName("host_key"),
+				"Invalid host_key",
+				fmt.Sprintf("host_key should be a public key such as \"ssh-ed25519 AAAA...\" or a fingerprint such as \"SHA256:...\": %v", err),
+			)
+		}
+	}
+
+	return diags
+}
+
+// hostKeyVerifier checks the key presented by the server and remembers why it was rejected,
+// so that a verification failure can be told apart from other handshake errors.
+type hostKeyVerifier struct {
+	options hostKeyOptions
+
+	mu  sync.Mutex
+	err error
+}
+
+func (o hostKeyOptions) verifier(root path.Path) (*hostKeyVerifier, diag.Diagnostics) {
+	diags := o.validate(root)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &hostKeyVerifier{
+		options: o,
+	}, diags
+}
+
+func (v *hostKeyVerifier) Callback(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	err := v.verify(hostname, remote, key)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.err = err
+
+	return err
+}
+
+// Err returns the reason the last presented host key was rejected, or nil.
+func (v *hostKeyVerifier) Err() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.err
+}
+
+func (v *hostKeyVerifier) verify(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if hostKey := stringValue(v.options.HostKey); hostKey != "" {
+		if fingerprint, found := strings.CutPrefix(hostKey, "SHA256:"); found {
+			if ssh.FingerprintSHA256(key) != "SHA256:"+fingerprint {
+				return fmt.Errorf("host %s presented %s key %s, but host_key pins %s", hostname, key.Type(), ssh.FingerprintSHA256(key), hostKey)
+			}
+			return nil
+		}
+
+		pinned, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(pinned.Marshal(), key.Marshal()) {
+			return fmt.Errorf("host %s presented %s key %s, but host_key pins %s key %s", hostname, key.Type(), ssh.FingerprintSHA256(key), pinned.Type(), ssh.FingerprintSHA256(pinned))
+		}
+		return nil
+	}
+
+	strict := v.options.strictHostKeyChecking()
+	if strict == strictHostKeyCheckingNo {
+		return nil
+	}
+
+	knownHostsFile, err := v.options.knownHostsFile()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(knownHostsFile); errors.Is(err, os.ErrNotExist) {
+		if strict == strictHostKeyCheckingAcceptNew {
+			return goph.AddKnownHost(hostname, remote, key, knownHostsFile)
+		}
+		return fmt.Errorf("known hosts file %s does not exist, and host %s is not trusted", knownHostsFile, hostname)
+	}
+
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return err
+	}
+
+	err = callback(hostname, remote, key)
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("host key of %s changed: presented %s key %s does not match %s line %d. This could mean a man in the middle attack", hostname, key.Type(), ssh.FingerprintSHA256(key), keyErr.Want[0].Filename, keyErr.Want[0].Line)
+		}
+		if strict == strictHostKeyCheckingAcceptNew {
+			return goph.AddKnownHost(hostname, remote, key, knownHostsFile)
+		}
+		return fmt.Errorf("host %s is not in %s. Presented %s key %s", hostname, knownHostsFile, key.Type(), ssh.FingerprintSHA256(key))
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"gotest.tools/assert"
+)
+
+var testRemote = &net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 22}
+
+func newTestVerifier(t *testing.T, options hostKeyOptions) *hostKeyVerifier {
+	verifier, diags := options.verifier(path.Empty())
+	assert.Assert(t, !diags.HasError(), "%v", diags)
+	return verifier
+}
+
+func TestHostKeyPinnedPublicKey(t *testing.T) {
+	_, signer := generateTestKey(t)
+	_, otherSigner := generateTestKey(t)
+
+	verifier := newTestVerifier(t, hostKeyOptions{
+		HostKey: types.StringValue(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+	})
+
+	assert.NilError(t, verifier.Callback("example.com:22", testRemote, signer.PublicKey()))
+	assert.ErrorContains(t, verifier.Callback("example.com:22", testRemote, otherSigner.PublicKey()), "host_key pins")
+	assert.ErrorContains(t, verifier.Err(), "host_key pins")
+}
+
+func TestHostKeyPinnedFingerprint(t *testing.T) {
+	_, signer := generateTestKey(t)
+	_, otherSigner := generateTestKey(t)
+
+	verifier := newTestVerifier(t, hostKeyOptions{
+		HostKey: types.StringValue(ssh.FingerprintSHA256(signer.PublicKey())),
+	})
+
+	assert.NilError(t, verifier.Callback("example.com:22", testRemote, signer.PublicKey()))
+	assert.ErrorContains(t, verifier.Callback("example.com:22", testRemote, otherSigner.PublicKey()), "host_key pins")
+}
+
+func TestHostKeyStrictRejectsUnknownHost(t *testing.T) {
+	_, signer := generateTestKey(t)
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	assert.NilError(t, os.WriteFile(knownHostsFile, []byte{}, 0600))
+
+	verifier := newTestVerifier(t, hostKeyOptions{
+		KnownHostsFile: types.StringValue(knownHostsFile),
+	})
+
+	assert.ErrorContains(t, verifier.Callback("example.com:22", testRemote, signer.PublicKey()), "is not in")
+}
+
+func TestHostKeyAcceptNew(t *testing.T) {
+	_, signer := generateTestKey(t)
+	_, otherSigner := generateTestKey(t)
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+
+	verifier := newTestVerifier(t, hostKeyOptions{
+		KnownHostsFile:        types.StringValue(knownHostsFile),
+		StrictHostKeyChecking: types.StringValue("accept-new"),
+	})
+
+	assert.NilError(t, verifier.Callback("example.com:22", testRemote, signer.PublicKey()))
+	assert.NilError(t, verifier.Callback("example.com:22", testRemote, signer.PublicKey()))
+	assert.ErrorContains(t, verifier.Callback("example.com:22", testRemote, otherSigner.PublicKey()), "changed")
+}
+
+func TestHostKeyInvalidStrictHostKeyChecking(t *testing.T) {
+	_, diags := hostKeyOptions{
+		StrictHostKeyChecking: types.StringValue("maybe"),
+	}.verifier(path.Empty())
+
+	assert.Assert(t, diags.HasError())
+	assert.Equal(t, "Invalid strict_host_key_checking", diags[0].Summary())
+}
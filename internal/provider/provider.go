@@ -2,9 +2,15 @@ package provider
 
 import (
 	"context"
+	"sync"
+	"terraform-provider-linux/internal/authorizedkeys"
+	"terraform-provider-linux/internal/directory"
 	"terraform-provider-linux/internal/file"
+	"terraform-provider-linux/internal/group"
 	"terraform-provider-linux/internal/user"
 	"terraform-provider-linux/internal/util"
+	"terraform-provider-linux/internal/util/local"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -12,7 +18,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/melbahja/goph"
 )
 
 // Ensure ScaffoldingProvider satisfies various provider interfaces.
@@ -28,6 +33,22 @@ func New(version string) func() provider.Provider {
 
 type LinuxProvider struct {
 	version string
+
+	mu sync.Mutex
+	// executors are the SSH connections opened by Configure, closed when the provider stops.
+	executors []*sshUtil.Executor
+}
+
+// Close closes every SSH connection the provider opened, including the ones to bastions.
+func (p *LinuxProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, executor := range p.executors {
+		executor.Close()
+	}
+	p.executors = nil
+	return nil
 }
 
 func (p *LinuxProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -36,126 +57,217 @@ func (p *LinuxProvider) Metadata(_ context.Context, _ provider.MetadataRequest,
 }
 
 type LinuxProviderModel struct {
-	Host       types.String `tfsdk:"host"`
-	Username   types.String `tfsdk:"username"`
-	PrivateKey types.String `tfsdk:"private_key"`
+	Connection            types.String   `tfsdk:"connection"`
+	Host                  types.String   `tfsdk:"host"`
+	Username              types.String   `tfsdk:"username"`
+	Password              types.String   `tfsdk:"password"`
+	PrivateKey            types.String   `tfsdk:"private_key"`
+	PrivateKeyPassphrase  types.String   `tfsdk:"private_key_passphrase"`
+	Certificate           types.String   `tfsdk:"certificate"`
+	Agent                 types.Bool     `tfsdk:"agent"`
+	HostKey               types.String   `tfsdk:"host_key"`
+	KnownHostsFile        types.String   `tfsdk:"known_hosts_file"`
+	StrictHostKeyChecking types.String   `tfsdk:"strict_host_key_checking"`
+	Port                  types.Int64    `tfsdk:"port"`
+	ConnectTimeout        types.String   `tfsdk:"connect_timeout"`
+	CommandTimeout        types.String   `tfsdk:"command_timeout"`
+	KeepaliveInterval     types.String   `tfsdk:"keepalive_interval"`
+	MaxRetries            types.Int64    `tfsdk:"max_retries"`
+	RetryBaseDelay        types.String   `tfsdk:"retry_base_delay"`
+	MaxSessions           types.Int64    `tfsdk:"max_sessions"`
+	Bastions              []BastionModel `tfsdk:"bastion"`
+	Become                *BecomeModel   `tfsdk:"become"`
 }
 
-func (p *LinuxProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
-	resp.Schema = schema.Schema{
-		Attributes: map[string]schema.Attribute{
-			"host": schema.StringAttribute{
-				Required: true,
-			},
-			"username": schema.StringAttribute{
-				Required: true,
-			},
-			"private_key": schema.StringAttribute{
-				Required:  true,
-				Sensitive: true,
-			},
-		},
+func (m *LinuxProviderModel) authOptions() authOptions {
+	return authOptions{
+		Password:             m.Password,
+		PrivateKey:           m.PrivateKey,
+		PrivateKeyPassphrase: m.PrivateKeyPassphrase,
+		Certificate:          m.Certificate,
+		Agent:                m.Agent,
 	}
 }
 
-func (p *LinuxProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	var config LinuxProviderModel
-	diags := req.Config.Get(ctx, &config)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+func (m *LinuxProviderModel) hostKeyOptions() hostKeyOptions {
+	return hostKeyOptions{
+		HostKey:               m.HostKey,
+		KnownHostsFile:        m.KnownHostsFile,
+		StrictHostKeyChecking: m.StrictHostKeyChecking,
 	}
+}
 
-	if config.Host.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("host"),
-			"Host unknown",
-			"Host is unknown",
-		)
+// hostAttributes returns the attributes describing how to reach and authenticate to a single host,
+// shared by the provider itself and each bastion.
+func hostAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"host": schema.StringAttribute{
+			Required: true,
+		},
+		"username": schema.StringAttribute{
+			Required: true,
+		},
+		"port": schema.Int64Attribute{
+			Description: "SSH port of the host. Defaults to 22",
+			Optional:    true,
+		},
+		"password": schema.StringAttribute{
+			Description: "Password for password or keyboard-interactive authentication",
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"private_key": schema.StringAttribute{
+			Description: "PEM or OpenSSH encoded private key",
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"private_key_passphrase": schema.StringAttribute{
+			Description: "Passphrase of an encrypted `private_key`",
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"certificate": schema.StringAttribute{
+			Description: "OpenSSH certificate signed for `private_key`, such as the content of `id_ed25519-cert.pub`",
+			Optional:    true,
+		},
+		"agent": schema.BoolAttribute{
+			Description: "Authenticate with the keys of the SSH agent at `SSH_AUTH_SOCK`",
+			Optional:    true,
+		},
+		"host_key": schema.StringAttribute{
+			Description: "Pinned public key of the host such as `ssh-ed25519 AAAA...`, or its fingerprint such as `SHA256:...`. Takes precedence over `known_hosts_file`",
+			Optional:    true,
+		},
+		"known_hosts_file": schema.StringAttribute{
+			Description: "Path of the known_hosts file used to verify the host. Defaults to `~/.ssh/known_hosts`",
+			Optional:    true,
+		},
+		"strict_host_key_checking": schema.StringAttribute{
+			Description: "One of `yes`, `no` or `accept-new`, with the same meaning as in OpenSSH. Defaults to `yes`",
+			Optional:    true,
+		},
 	}
+}
 
-	if config.Username.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("username"),
-			"Username unknown",
-			"Username is unknown",
-		)
+func (p *LinuxProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	attributes := hostAttributes()
+	attributes["connection"] = schema.StringAttribute{
+		Description: "Either `ssh` to manage `host`, or `local` to run commands on the machine Terraform runs on, without SSH. Defaults to `ssh`",
+		Optional:    true,
 	}
-
-	if config.PrivateKey.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("private_key"),
-			"PrivateKey unknown",
-			"PrivateKey is unknown",
-		)
+	// host and username are only required by the ssh connection, which is checked in Configure.
+	attributes["host"] = schema.StringAttribute{
+		Optional: true,
 	}
-
-	if resp.Diagnostics.HasError() {
-		return
+	attributes["username"] = schema.StringAttribute{
+		Optional: true,
 	}
-
-	var host string
-	var username string
-	var privateKey string
-
-	if !config.Host.IsNull() {
-		host = config.Host.ValueString()
+	attributes["connect_timeout"] = schema.StringAttribute{
+		Description: "Maximum time to establish the connection, such as `30s`. Defaults to `20s`",
+		Optional:    true,
 	}
-
-	if !config.Username.IsNull() {
-		username = config.Username.ValueString()
+	attributes["command_timeout"] = schema.StringAttribute{
+		Description: "Maximum run time of each remote command, such as `5m`. Commands are not limited by default",
+		Optional:    true,
 	}
-
-	if !config.PrivateKey.IsNull() {
-		privateKey = config.PrivateKey.ValueString()
+	attributes["keepalive_interval"] = schema.StringAttribute{
+		Description: "Interval between keepalive requests on an idle connection, such as `30s`. Disabled by default",
+		Optional:    true,
 	}
-
-	if host == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("host"),
-			"Empty host",
-			"Please specify host",
-		)
+	attributes["max_retries"] = schema.Int64Attribute{
+		Description: "Number of times a command is retried after the connection broke, once it is reestablished. Commands that fail on the host are never retried. Defaults to 2",
+		Optional:    true,
 	}
-
-	if username == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("username"),
-			"Empty username",
-			"Please specify username",
-		)
+	attributes["retry_base_delay"] = schema.StringAttribute{
+		Description: "Wait before the first retry, doubled before each following one, such as `1s`. Defaults to `2s`",
+		Optional:    true,
+	}
+	attributes["max_sessions"] = schema.Int64Attribute{
+		Description: "Maximum number of concurrent sessions on the connection, further commands wait for a free one. Should not exceed `MaxSessions` of the SSH server. Defaults to 10",
+		Optional:    true,
 	}
 
-	if privateKey == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("private_key"),
-			"Empty private_key",
-			"Please specify private key",
-		)
+	resp.Schema = schema.Schema{
+		Attributes: attributes,
+		Blocks: map[string]schema.Block{
+			"bastion": schema.ListNestedBlock{
+				Description: "Jump hosts the connection is tunneled through, in order. The first bastion is dialed directly and every following host, including `host`, is reached through the previous one",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: hostAttributes(),
+				},
+			},
+			"become": schema.SingleNestedBlock{
+				Description: "Run every command as another user, for logins that are not root. Files are transferred through a private temporary directory of the login user, which `become_user` must be able to read and write, as `root` can",
+				Attributes: map[string]schema.Attribute{
+					"method": schema.StringAttribute{
+						Description: "One of `sudo`, `doas` or `su`. Defaults to `sudo`",
+						Optional:    true,
+					},
+					"become_user": schema.StringAttribute{
+						Description: "User commands run as. Defaults to `root`",
+						Optional:    true,
+					},
+					"become_password": schema.StringAttribute{
+						Description: "Password fed to `sudo` on stdin. Without it, `sudo` must not prompt for a password. Not supported with `doas` and `su`, which only read passwords from a terminal: configure them to run without one for the login user instead, such as `permit nopass` in doas.conf",
+						Optional:    true,
+						Sensitive:   true,
+					},
+				},
+			},
+		},
 	}
+}
 
+func (p *LinuxProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config LinuxProviderModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	auth, err := goph.RawKey(privateKey, "")
-	if err != nil {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("private_key"),
-			"Failed to create auth info",
-			err.Error(),
-		)
+	connection, diags := config.connectionOptions()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	sshClient, err := goph.New(username, host, auth)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to create client", err.Error())
+	become, diags := config.Become.become(path.Root("become"))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	var executor util.Executor
+	switch connection.Type {
+	case connectionLocal:
+		if len(config.Bastions) > 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("bastion"),
+				"Bastion with local connection",
+				"bastion can only be used with the \"ssh\" connection",
+			)
+			return
+		}
+		executor = local.NewExecutor()
+	default:
+		sshExecutor, diags := newSshExecutor(&config, connection)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		p.mu.Lock()
+		p.executors = append(p.executors, sshExecutor)
+		p.mu.Unlock()
+		executor = sshExecutor
+	}
+
 	providerData := &util.LinuxProviderData{
-		SshClient: sshClient,
+		Executor:       executor,
+		Become:         become,
+		CommandTimeout: connection.CommandTimeout,
+		Retry:          connection.Retry,
 	}
 	resp.DataSourceData = providerData
 	resp.ResourceData = providerData
@@ -164,12 +276,20 @@ func (p *LinuxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 func (p *LinuxProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		user.NewUserDataSource,
+		user.NewUsersDataSource,
 		file.NewFileDataSource,
+		group.NewGroupDataSource,
 	}
 }
 
 func (p *LinuxProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		user.NewUserResource,
+		file.NewFileResource,
+		file.NewFileAclResource,
+		directory.NewDirectoryResource,
+		group.NewGroupResource,
+		group.NewGroupMembershipResource,
+		authorizedkeys.NewAuthorizedKeysResource,
 	}
 }
@@ -4,10 +4,23 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"terraform-provider-linux/internal/util"
+	"terraform-provider-linux/internal/util/local"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -15,11 +28,423 @@ import (
 // CLI command executed to create a provider server to which the CLI can
 // reattach.
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"scaffolding": providerserver.NewProtocol6WithError(New("test")()),
+	"linux": providerserver.NewProtocol6WithError(New("test")()),
 }
 
+// Acceptance tests run as root against the host described by these variables. They create and remove users,
+// groups and files, so the host should be disposable, such as the container started by CI.
+const (
+	// testAccEnvConnection is either "ssh", the default, or "local" to manage the machine running the tests.
+	testAccEnvConnection = "LINUX_ACC_CONNECTION"
+	testAccEnvHost       = "LINUX_ACC_HOST"
+	testAccEnvPort       = "LINUX_ACC_PORT"
+	testAccEnvPassword   = "LINUX_ACC_PASSWORD"
+	// testAccEnvPrivateKeyFile is the path of an unencrypted private key, used instead of the password.
+	testAccEnvPrivateKeyFile = "LINUX_ACC_PRIVATE_KEY_FILE"
+	// testAccEnvHostKey pins the host key in authorized_keys format. Host keys are not verified without it.
+	testAccEnvHostKey = "LINUX_ACC_HOST_KEY"
+)
+
 func testAccPreCheck(t *testing.T) {
-	// You can add code here to run prior to any test case execution, for example assertions
-	// about the appropriate environment variables being set are common to see in a pre-check
-	// function.
+	if os.Getenv(testAccEnvConnection) == connectionLocal {
+		if os.Geteuid() != 0 {
+			t.Fatalf("Acceptance tests with %s=%s must run as root", testAccEnvConnection, connectionLocal)
+		}
+		return
+	}
+	if os.Getenv(testAccEnvHost) == "" {
+		t.Fatalf("%s must be set to the host acceptance tests run against, or %s to %q", testAccEnvHost, testAccEnvConnection, connectionLocal)
+	}
+	if os.Getenv(testAccEnvPassword) == "" && os.Getenv(testAccEnvPrivateKeyFile) == "" {
+		t.Fatalf("Either %s or %s must be set", testAccEnvPassword, testAccEnvPrivateKeyFile)
+	}
+}
+
+// testAccTarget is the host acceptance tests run against. Tests inspect and change it only through commands,
+// so they verify the behaviour of real Linux tools.
+type testAccTarget struct {
+	t        *testing.T
+	config   LinuxProviderModel
+	linuxCtx util.LinuxContext
+}
+
+// testAccHost connects to the host acceptance tests run against, as configured by the LINUX_ACC_* variables.
+func testAccHost(t *testing.T) *testAccTarget {
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.EnvTfAcc)
+	}
+	testAccPreCheck(t)
+
+	config := LinuxProviderModel{
+		Connection:            types.StringNull(),
+		Host:                  types.StringNull(),
+		Username:              types.StringValue("root"),
+		Password:              types.StringNull(),
+		PrivateKey:            types.StringNull(),
+		PrivateKeyPassphrase:  types.StringNull(),
+		Certificate:           types.StringNull(),
+		Agent:                 types.BoolNull(),
+		HostKey:               types.StringNull(),
+		KnownHostsFile:        types.StringNull(),
+		StrictHostKeyChecking: types.StringNull(),
+		Port:                  types.Int64Null(),
+		ConnectTimeout:        types.StringNull(),
+		CommandTimeout:        types.StringNull(),
+		KeepaliveInterval:     types.StringNull(),
+		MaxRetries:            types.Int64Null(),
+		RetryBaseDelay:        types.StringNull(),
+		MaxSessions:           types.Int64Null(),
+	}
+	if value := os.Getenv(testAccEnvConnection); value != "" {
+		config.Connection = types.StringValue(value)
+	}
+	if value := os.Getenv(testAccEnvHost); value != "" {
+		config.Host = types.StringValue(value)
+	}
+	if value := os.Getenv(testAccEnvPort); value != "" {
+		port, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			t.Fatalf("Invalid %s: %v", testAccEnvPort, err)
+		}
+		config.Port = types.Int64Value(port)
+	}
+	if value := os.Getenv(testAccEnvPassword); value != "" {
+		config.Password = types.StringValue(value)
+	}
+	if value := os.Getenv(testAccEnvPrivateKeyFile); value != "" {
+		privateKey, err := os.ReadFile(value)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", testAccEnvPrivateKeyFile, err)
+		}
+		config.PrivateKey = types.StringValue(string(privateKey))
+	}
+	if value := os.Getenv(testAccEnvHostKey); value != "" {
+		config.HostKey = types.StringValue(value)
+	} else {
+		config.StrictHostKeyChecking = types.StringValue(strictHostKeyCheckingNo)
+	}
+
+	connection, diags := config.connectionOptions()
+	if diags.HasError() {
+		t.Fatalf("Invalid acceptance test settings: %v", diags)
+	}
+	var executor util.Executor
+	if connection.Type == connectionLocal {
+		executor = local.NewExecutor()
+	} else {
+		executor, diags = newSshExecutor(&config, connection)
+		if diags.HasError() {
+			t.Fatalf("Failed to connect to the acceptance test host: %v", diags)
+		}
+	}
+
+	return &testAccTarget{
+		t:        t,
+		config:   config,
+		linuxCtx: util.NewLinuxContext(context.Background(), &util.LinuxProviderData{Executor: executor}),
+	}
+}
+
+// testAccProviderConfig returns the provider block connecting to host as root.
+func testAccProviderConfig(host *testAccTarget) string {
+	lines := []string{}
+	setString := func(name string, value types.String) {
+		if !value.IsNull() {
+			lines = append(lines, fmt.Sprintf("  %s = %q", name, value.ValueString()))
+		}
+	}
+	setString("connection", host.config.Connection)
+	if host.config.Connection.ValueString() != connectionLocal {
+		setString("host", host.config.Host)
+		setString("username", host.config.Username)
+		if !host.config.Port.IsNull() {
+			lines = append(lines, fmt.Sprintf("  port = %d", host.config.Port.ValueInt64()))
+		}
+		setString("password", host.config.Password)
+		setString("private_key", host.config.PrivateKey)
+		setString("host_key", host.config.HostKey)
+		setString("strict_host_key_checking", host.config.StrictHostKeyChecking)
+	}
+
+	return "\nprovider \"linux\" {\n" + strings.Join(lines, "\n") + "\n}\n"
+}
+
+// run runs argv on the host and returns its output.
+func (h *testAccTarget) run(argv ...string) (string, error) {
+	_, result, commonError := sshUtil.RunCommand(h.linuxCtx, sshUtil.Command(argv...), nil)
+	if commonError != nil {
+		return "", fmt.Errorf("%s: %v", strings.Join(argv, " "), commonError.Diagnostics)
+	}
+	return result.Stdout, nil
+}
+
+// mustRun runs argv on the host, failing the test when it fails.
+func (h *testAccTarget) mustRun(argv ...string) string {
+	h.t.Helper()
+
+	stdout, err := h.run(argv...)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return stdout
+}
+
+// lookup runs argv, which exits with status notFound when what it looks for does not exist.
+func (h *testAccTarget) lookup(notFound int, argv ...string) (string, bool, error) {
+	found := true
+	errorhandler := func(result *util.CommandResult, err error) (util.Status, *util.CommonError) {
+		if result.Exited(notFound) {
+			found = false
+			return util.Success, nil
+		}
+		return util.Bottom, nil
+	}
+	_, result, commonError := sshUtil.RunCommand(h.linuxCtx, sshUtil.Command(argv...), errorhandler)
+	if commonError != nil {
+		return "", false, fmt.Errorf("%s: %v", strings.Join(argv, " "), commonError.Diagnostics)
+	}
+	return result.Stdout, found, nil
+}
+
+// writeFile replaces remotePath with content and mode, as root.
+func (h *testAccTarget) writeFile(remotePath string, content string, mode os.FileMode) {
+	h.t.Helper()
+
+	command := sshUtil.Command("cat") + " > " + sshUtil.Command(remotePath)
+	if _, _, commonError := sshUtil.RunCommandWithStdin(h.linuxCtx, command, content, nil); commonError != nil {
+		h.t.Fatalf("Failed to write %s: %v", remotePath, commonError.Diagnostics)
+	}
+	h.mustRun("chmod", fmt.Sprintf("%o", mode), "--", remotePath)
+}
+
+func (h *testAccTarget) readFile(remotePath string) (string, error) {
+	return h.run("cat", "--", remotePath)
+}
+
+// testAccStat is the type, permission bits and ownership of a path on the host. Symbolic links are not followed.
+type testAccStat struct {
+	Type string
+	Mode os.FileMode
+	Uid  int64
+	Gid  int64
+}
+
+// stat returns nil when remotePath does not exist.
+func (h *testAccTarget) stat(remotePath string) (*testAccStat, error) {
+	stdout, found, err := h.lookup(1, "stat", "-c", "%F:%a:%u:%g", "--", remotePath)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.TrimSpace(stdout), ":")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid stat output %q", stdout)
+	}
+	mode, err := strconv.ParseUint(fields[1], 8, 32)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	gid, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &testAccStat{Type: fields[0], Mode: os.FileMode(mode), Uid: uid, Gid: gid}, nil
+}
+
+// testAccUser is an account on the host, from passwd and shadow. Days of shadow that are not set are -1.
+type testAccUser struct {
+	Name         string
+	Uid          int64
+	Gid          int64
+	Comment      string
+	Home         string
+	Shell        string
+	PasswordHash string
+	MinDays      int64
+	MaxDays      int64
+	WarnDays     int64
+	Expires      int64
+}
+
+func parseShadowDays(value string) (int64, error) {
+	if value == "" {
+		return -1, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// user returns nil when the account does not exist.
+func (h *testAccTarget) user(name string) (*testAccUser, error) {
+	passwd, found, err := h.lookup(2, "getent", "passwd", name)
+	if err != nil || !found {
+		return nil, err
+	}
+	shadow, err := h.run("getent", "shadow", name)
+	if err != nil {
+		return nil, err
+	}
+
+	passwdFields := strings.Split(strings.TrimSpace(passwd), ":")
+	shadowFields := strings.Split(strings.TrimSpace(shadow), ":")
+	if len(passwdFields) != 7 || len(shadowFields) != 9 {
+		return nil, fmt.Errorf("invalid account %q, %q", passwd, shadow)
+	}
+
+	user := &testAccUser{
+		Name:         passwdFields[0],
+		Comment:      passwdFields[4],
+		Home:         passwdFields[5],
+		Shell:        passwdFields[6],
+		PasswordHash: shadowFields[1],
+	}
+	for _, field := range []struct {
+		value  string
+		target *int64
+		parse  func(string) (int64, error)
+	}{
+		{passwdFields[2], &user.Uid, func(value string) (int64, error) { return strconv.ParseInt(value, 10, 64) }},
+		{passwdFields[3], &user.Gid, func(value string) (int64, error) { return strconv.ParseInt(value, 10, 64) }},
+		{shadowFields[3], &user.MinDays, parseShadowDays},
+		{shadowFields[4], &user.MaxDays, parseShadowDays},
+		{shadowFields[5], &user.WarnDays, parseShadowDays},
+		{shadowFields[7], &user.Expires, parseShadowDays},
+	} {
+		if *field.target, err = field.parse(field.value); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// groups returns the supplementary groups of the account, sorted.
+func (h *testAccTarget) groups(name string) ([]string, error) {
+	primary, err := h.run("id", "-gn", "--", name)
+	if err != nil {
+		return nil, err
+	}
+	all, err := h.run("id", "-Gn", "--", name)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []string{}
+	for _, group := range strings.Fields(all) {
+		if group != strings.TrimSpace(primary) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// testAccGroup is a group on the host. Members are sorted.
+type testAccGroup struct {
+	Name    string
+	Gid     int64
+	Members []string
+}
+
+// group returns nil when the group does not exist.
+func (h *testAccTarget) group(name string) (*testAccGroup, error) {
+	stdout, found, err := h.lookup(2, "getent", "group", name)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.TrimSpace(stdout), ":")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid group %q", stdout)
+	}
+	gid, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	members := []string{}
+	if fields[3] != "" {
+		members = strings.Split(fields[3], ",")
+	}
+	sort.Strings(members)
+	return &testAccGroup{Name: fields[0], Gid: gid, Members: members}, nil
+}
+
+// addUser creates an account outside of Terraform, which is removed with its home when the test ends.
+func (h *testAccTarget) addUser(name string, uid int64) {
+	h.t.Helper()
+
+	h.mustRun("useradd", "--uid", strconv.FormatInt(uid, 10), "--user-group", "--create-home", "--", name)
+	h.t.Cleanup(func() {
+		h.removeUser(name)
+	})
+}
+
+// removeUser deletes the account and its home if it still exists.
+func (h *testAccTarget) removeUser(name string) {
+	if _, _, err := h.lookup(6, "userdel", "--remove", "--", name); err != nil {
+		h.t.Errorf("Failed to remove user %s: %v", name, err)
+	}
+}
+
+// addGroup creates a group outside of Terraform, which is removed when the test ends.
+func (h *testAccTarget) addGroup(name string, gid int64) {
+	h.t.Helper()
+
+	h.mustRun("groupadd", "--gid", strconv.FormatInt(gid, 10), "--", name)
+	h.t.Cleanup(func() {
+		h.removeGroup(name)
+	})
+}
+
+// removeGroup deletes the group if it still exists.
+func (h *testAccTarget) removeGroup(name string) {
+	if _, _, err := h.lookup(6, "groupdel", "--", name); err != nil {
+		h.t.Errorf("Failed to remove group %s: %v", name, err)
+	}
+}
+
+// removeOnCleanup removes remotePaths when the test ends, such as home directories kept by userdel.
+func (h *testAccTarget) removeOnCleanup(remotePaths ...string) {
+	h.t.Cleanup(func() {
+		if _, err := h.run(append([]string{"rm", "-rf", "--"}, remotePaths...)...); err != nil {
+			h.t.Errorf("Failed to clean up: %v", err)
+		}
+	})
+}
+
+// setMembers replaces the members of group outside of Terraform.
+func (h *testAccTarget) setMembers(group string, members ...string) {
+	h.t.Helper()
+
+	h.mustRun("gpasswd", "--members", strings.Join(members, ","), "--", group)
+}
+
+// testAccCheckNoResources fails when resources of resourceType are left in the state,
+// and then runs check to verify the remote state after destroy.
+func testAccCheckNoResources(resourceType string, check func(attributes map[string]string) error) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		for _, resourceState := range state.RootModule().Resources {
+			if resourceState.Type != resourceType {
+				continue
+			}
+			if err := check(resourceState.Primary.Attributes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckNotExists fails when remotePath exists on the host.
+func testAccCheckNotExists(host *testAccTarget, remotePath string) error {
+	stat, err := host.stat(remotePath)
+	if err != nil {
+		return err
+	}
+	if stat != nil {
+		return errors.New(remotePath + " still exists on the host")
+	}
+	return nil
 }
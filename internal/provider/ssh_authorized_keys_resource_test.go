@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"golang.org/x/crypto/ssh"
+)
+
+// testAccAuthorizedKey returns a public key in authorized_keys format, derived from seed.
+func testAccAuthorizedKey(t *testing.T, seed byte) string {
+	privateKey := ed25519.NewKeyFromSeed([]byte(strings.Repeat(string([]byte{seed}), ed25519.SeedSize)))
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+}
+
+func testAccAuthorizedKeysResourceConfig(host *testAccTarget, exclusive bool, keys string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "alice" {
+  username    = "alice"
+  uid         = 1900
+  create_home = true
+}
+
+resource "linux_ssh_authorized_keys" "test" {
+  username  = linux_user.alice.username
+  exclusive = %t
+  keys      = [%s]
+}
+`, exclusive, keys)
+}
+
+// testAccCheckAuthorizedKeys verifies the lines of authorized_keys of alice and the permissions of ~/.ssh.
+func testAccCheckAuthorizedKeys(host *testAccTarget, lines ...string) resource.TestCheckFunc {
+	return resource.ComposeAggregateTestCheckFunc(
+		testAccCheckFile(host, "/home/alice/.ssh/authorized_keys", strings.Join(lines, "\n")+"\n", 0600, 1900),
+		func(*terraform.State) error {
+			stat, err := host.stat("/home/alice/.ssh")
+			if err != nil {
+				return err
+			}
+			if stat.Mode != 0700 {
+				return fmt.Errorf("expected mode 700 for /home/alice/.ssh, got %o", stat.Mode)
+			}
+			if stat.Uid != 1900 {
+				return fmt.Errorf("expected owner 1900 for /home/alice/.ssh, got %d", stat.Uid)
+			}
+			return nil
+		},
+	)
+}
+
+func testAccCheckAuthorizedKeysDestroy(host *testAccTarget) resource.TestCheckFunc {
+	return testAccCheckNoResources("linux_ssh_authorized_keys", func(attributes map[string]string) error {
+		return testAccCheckNotExists(host, attributes["path"])
+	})
+}
+
+// testAccAppendAuthorizedKey adds line to authorized_keys of alice, as if it was added by hand.
+func testAccAppendAuthorizedKey(host *testAccTarget, line string) {
+	content, err := host.readFile("/home/alice/.ssh/authorized_keys")
+	if err != nil {
+		host.t.Fatal(err)
+	}
+	host.writeFile("/home/alice/.ssh/authorized_keys", content+line+"\n", 0600)
+}
+
+func TestAccAuthorizedKeysResource(t *testing.T) {
+	host := testAccHost(t)
+	host.removeOnCleanup("/home/alice")
+	laptop, deploy, manual := testAccAuthorizedKey(t, 1), testAccAuthorizedKey(t, 2), testAccAuthorizedKey(t, 3)
+	keys := fmt.Sprintf(`{ key = %q }, { key = %q, from = "10.0.0.0/8", command = "/usr/bin/deploy", no_pty = true }`, laptop+" laptop", deploy)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckAuthorizedKeysDestroy(host),
+			testAccCheckUserDestroy(host),
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccAuthorizedKeysResourceConfig(host, false, keys),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_ssh_authorized_keys.test", "path", "/home/alice/.ssh/authorized_keys"),
+					resource.TestCheckResourceAttr("linux_ssh_authorized_keys.test", "keys.#", "2"),
+					resource.TestCheckResourceAttr("linux_ssh_authorized_keys.test", "keys.0.no_pty", "false"),
+					resource.TestCheckResourceAttr("linux_ssh_authorized_keys.test", "keys.1.from", "10.0.0.0/8"),
+					testAccCheckAuthorizedKeys(host,
+						laptop+" laptop",
+						`from="10.0.0.0/8",command="/usr/bin/deploy",no-pty `+deploy,
+					),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "linux_ssh_authorized_keys.test",
+				ImportState:                          true,
+				ImportStateId:                        "alice",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "username",
+			},
+			// Keys added outside of Terraform are left alone without exclusive
+			{
+				PreConfig: func() {
+					testAccAppendAuthorizedKey(host, manual+" manual")
+				},
+				Config:   testAccAuthorizedKeysResourceConfig(host, false, keys),
+				PlanOnly: true,
+			},
+			// Exclusive removes them, as well as keys that are no longer declared
+			{
+				Config: testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_ssh_authorized_keys.test", "keys.#", "1"),
+					testAccCheckAuthorizedKeys(host, laptop+" laptop"),
+				),
+			},
+			// Drift testing of unmanaged keys, options and permissions
+			{
+				PreConfig: func() {
+					testAccAppendAuthorizedKey(host, manual)
+				},
+				Config:             testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					host.writeFile("/home/alice/.ssh/authorized_keys", "no-pty "+laptop+" laptop\n", 0600)
+				},
+				Config:             testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					host.writeFile("/home/alice/.ssh/authorized_keys", laptop+" laptop\n", 0644)
+				},
+				Config:             testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Apply reverts the drift
+			{
+				Config: testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				Check:  testAccCheckAuthorizedKeys(host, laptop+" laptop"),
+			},
+			// A link in place of the file is refused rather than followed
+			{
+				PreConfig: func() {
+					host.mustRun("rm", "--", "/home/alice/.ssh/authorized_keys")
+					host.mustRun("ln", "-s", "/etc/shadow", "/home/alice/.ssh/authorized_keys")
+				},
+				Config:      testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				ExpectError: regexp.MustCompile("Unexpected file type"),
+			},
+			// A ~/.ssh owned by root is handed over to the user
+			{
+				PreConfig: func() {
+					host.mustRun("rm", "--", "/home/alice/.ssh/authorized_keys")
+					host.mustRun("chown", "root:root", "--", "/home/alice/.ssh")
+				},
+				Config: testAccAuthorizedKeysResourceConfig(host, true, fmt.Sprintf(`{ key = %q }`, laptop+" laptop")),
+				Check:  testAccCheckAuthorizedKeys(host, laptop+" laptop"),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,510 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"gotest.tools/assert"
+)
+
+func testAccUserResourceConfig(host *testAccTarget, user testAccUser) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "test" {
+  username = %q
+  uid      = %d
+  comment  = %q
+  home     = %q
+  shell    = %q
+  groups   = ["wheel"]
+}
+`, user.Name, user.Uid, user.Comment, user.Home, user.Shell)
+}
+
+// testAccCheckUser verifies the account on the host and its supplementary groups.
+func testAccCheckUser(host *testAccTarget, expected testAccUser, groups ...string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		user, err := host.user(expected.Name)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("user %s does not exist on the host", expected.Name)
+		}
+		if *user != expected {
+			return fmt.Errorf("expected user %+v on the host, got %+v", expected, *user)
+		}
+		actual, err := host.groups(expected.Name)
+		if err != nil {
+			return err
+		}
+		if strings.Join(actual, ",") != strings.Join(groups, ",") {
+			return fmt.Errorf("expected groups %v for user %s, got %v", groups, expected.Name, actual)
+		}
+		return nil
+	}
+}
+
+func testAccCheckUserDestroy(host *testAccTarget) resource.TestCheckFunc {
+	return testAccCheckNoResources("linux_user", func(attributes map[string]string) error {
+		user, err := host.user(attributes["username"])
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			return fmt.Errorf("user %s still exists on the host", user.Name)
+		}
+		return nil
+	})
+}
+
+func TestAccUserResource(t *testing.T) {
+	host := testAccHost(t)
+	host.addGroup("wheel", 3010)
+	host.addGroup("docker", 3020)
+	host.removeOnCleanup("/srv/alice", "/home/alice")
+
+	alice := testAccUser{Name: "alice", Uid: 1500, Gid: 1500, Comment: "Alice", Home: "/srv/alice", Shell: "/bin/bash", PasswordHash: "!", MaxDays: 99999, WarnDays: 7, Expires: -1}
+	config := testAccUserResourceConfig(host, alice)
+
+	steps := []resource.TestStep{
+		// Create and Read testing
+		{
+			Config: config,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("linux_user.test", "username", "alice"),
+				resource.TestCheckResourceAttr("linux_user.test", "uid", "1500"),
+				resource.TestCheckResourceAttr("linux_user.test", "gid", "1500"),
+				resource.TestCheckResourceAttr("linux_user.test", "comment", "Alice"),
+				resource.TestCheckResourceAttr("linux_user.test", "home", "/srv/alice"),
+				resource.TestCheckResourceAttr("linux_user.test", "shell", "/bin/bash"),
+				resource.TestCheckTypeSetElemAttr("linux_user.test", "groups.*", "wheel"),
+				resource.TestCheckResourceAttr("linux_user.test", "system", "false"),
+				testAccCheckUser(host, alice, "wheel"),
+			),
+		},
+		// ImportState testing
+		{
+			ResourceName:                         "linux_user.test",
+			ImportState:                          true,
+			ImportStateId:                        "alice",
+			ImportStateVerify:                    true,
+			ImportStateVerifyIdentifierAttribute: "username",
+			// locked is only known after apply when it is not configured.
+			ImportStateVerifyIgnore: []string{"locked"},
+		},
+	}
+
+	// Drift testing of every attribute read back from the host, each followed by an apply reverting it
+	drifts := [][]string{
+		{"--uid", "1600"},
+		{"--comment", "Mallory"},
+		{"--home", "/home/alice", "--move-home"},
+		{"--shell", "/bin/sh"},
+		{"--append", "--groups", "docker"},
+	}
+	for _, drift := range drifts {
+		drift := drift
+		steps = append(steps,
+			resource.TestStep{
+				PreConfig: func() {
+					host.mustRun(append(append([]string{"usermod"}, drift...), "--", "alice")...)
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: config,
+				Check:  testAccCheckUser(host, alice, "wheel"),
+			},
+		)
+	}
+
+	// Update and Read testing, keeping the id of the resource when the uid changes
+	bob := alice
+	bob.Uid = 1700
+	bob.Shell = "/bin/zsh"
+	steps = append(steps, resource.TestStep{
+		Config: testAccUserResourceConfig(host, bob),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("linux_user.test", "id", "1500"),
+			resource.TestCheckResourceAttr("linux_user.test", "uid", "1700"),
+			resource.TestCheckResourceAttr("linux_user.test", "shell", "/bin/zsh"),
+			testAccCheckUser(host, bob, "wheel"),
+		),
+	})
+	// Delete testing automatically occurs in TestCase
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(host),
+		Steps:                    steps,
+	})
+}
+
+func testAccUserPasswordConfig(host *testAccTarget, passwordHash string, locked bool) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "test" {
+  username           = "carol"
+  uid                = 1900
+  password_hash      = %q
+  locked             = %t
+  expires            = "2030-01-01"
+  password_max_days  = 90
+  password_min_days  = 1
+  password_warn_days = 14
+}
+`, passwordHash, locked)
+}
+
+func TestAccUserResourcePassword(t *testing.T) {
+	host := testAccHost(t)
+
+	passwordHash := "$6$salt$hash"
+	carol := testAccUser{Name: "carol", Uid: 1900, Gid: 1900, Home: "/home/carol", Shell: "/bin/sh", PasswordHash: passwordHash, MinDays: 1, MaxDays: 90, WarnDays: 14, Expires: 21915}
+	lockedCarol := carol
+	lockedCarol.PasswordHash = "!" + passwordHash
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(host),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccUserPasswordConfig(host, passwordHash, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_user.test", "password_hash", passwordHash),
+					resource.TestCheckResourceAttr("linux_user.test", "locked", "false"),
+					resource.TestCheckResourceAttr("linux_user.test", "expires", "2030-01-01"),
+					resource.TestCheckResourceAttr("linux_user.test", "password_max_days", "90"),
+					testAccCheckUser(host, carol),
+				),
+			},
+			// ImportState testing. The hash is never read back from the host.
+			{
+				ResourceName:                         "linux_user.test",
+				ImportState:                          true,
+				ImportStateId:                        "carol",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "username",
+				ImportStateVerifyIgnore:              []string{"password_hash"},
+			},
+			// Drift testing of the hash, the lock and the expiry
+			{
+				PreConfig: func() {
+					host.mustRun("usermod", "--password", "$6$other$hash", "--", "carol")
+				},
+				Config:             testAccUserPasswordConfig(host, passwordHash, false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					host.mustRun("usermod", "--password", passwordHash, "--", "carol")
+					host.mustRun("usermod", "--lock", "--", "carol")
+				},
+				Config:             testAccUserPasswordConfig(host, passwordHash, false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					host.mustRun("usermod", "--unlock", "--", "carol")
+					host.mustRun("chage", "--expiredate", "-1", "--", "carol")
+				},
+				Config:             testAccUserPasswordConfig(host, passwordHash, false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Update and Read testing, which also reverts the drift
+			{
+				Config: testAccUserPasswordConfig(host, passwordHash, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_user.test", "locked", "true"),
+					resource.TestCheckResourceAttr("linux_user.test", "password_hash", passwordHash),
+					testAccCheckUser(host, lockedCarol),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccUserLockConfig(host *testAccTarget, comment string, attributes string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "test" {
+  username = "frank"
+  uid      = 2200
+  comment  = %q
+  %s
+}
+`, comment, attributes)
+}
+
+// testAccExpectKnownValue is a plan check failing when attribute of resourceAddress is unknown in the plan.
+type testAccExpectKnownValue struct {
+	resourceAddress string
+	attribute       string
+}
+
+func (e testAccExpectKnownValue) CheckPlan(_ context.Context, req plancheck.CheckPlanRequest, resp *plancheck.CheckPlanResponse) {
+	for _, change := range req.Plan.ResourceChanges {
+		if change.Address != e.resourceAddress {
+			continue
+		}
+		afterUnknown, _ := change.Change.AfterUnknown.(map[string]interface{})
+		if unknown, _ := afterUnknown[e.attribute].(bool); unknown {
+			resp.Error = fmt.Errorf("expected %s.%s to be known in the plan", e.resourceAddress, e.attribute)
+		}
+		return
+	}
+	resp.Error = fmt.Errorf("%s not found in the plan", e.resourceAddress)
+}
+
+func TestAccUserResourceLock(t *testing.T) {
+	host := testAccHost(t)
+	passwordHash := "$6$salt$hash"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(host),
+		Steps: []resource.TestStep{
+			// Accounts are created without a password, which counts as locked
+			{
+				Config: testAccUserLockConfig(host, "Frank", ""),
+				Check:  resource.TestCheckResourceAttr("linux_user.test", "locked", "true"),
+			},
+			// The lock is kept from the state when other attributes change
+			{
+				Config: testAccUserLockConfig(host, "Frank Jr", ""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("linux_user.test", plancheck.ResourceActionUpdate),
+						testAccExpectKnownValue{"linux_user.test", "locked"},
+					},
+				},
+				Check: resource.TestCheckResourceAttr("linux_user.test", "locked", "true"),
+			},
+			// Unlocking an account without a password is refused instead of silently ignored
+			{
+				Config:      testAccUserLockConfig(host, "Frank Jr", "locked = false"),
+				ExpectError: regexp.MustCompile("Cannot unlock user without password"),
+			},
+			// Setting a password replaces the lock when locked is not configured
+			{
+				Config: testAccUserLockConfig(host, "Frank Jr", fmt.Sprintf("password_hash = %q", passwordHash)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownValue("linux_user.test", tfjsonpath.New("locked")),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("linux_user.test", "locked", "false"),
+			},
+			{
+				Config:   testAccUserLockConfig(host, "Frank Jr", fmt.Sprintf("password_hash = %q\n  locked = false", passwordHash)),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccUserResourceDeletedOutside(t *testing.T) {
+	host := testAccHost(t)
+	host.removeOnCleanup("/home/dave")
+	dave := testAccUser{Name: "dave", Uid: 2000, Gid: 2000, Home: "/home/dave", Shell: "/bin/sh", PasswordHash: "!", MaxDays: 99999, WarnDays: 7, Expires: -1}
+	config := testAccProviderConfig(host) + `
+resource "linux_user" "test" {
+  username = "dave"
+  uid      = 2000
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(host),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckUser(host, dave),
+			},
+			// The user is deleted over SSH, so refresh removes it from the state and a recreate is planned
+			{
+				PreConfig: func() {
+					host.mustRun("userdel", "dave")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check:  testAccCheckUser(host, dave),
+			},
+			// create_home only applies at creation, so changing it replaces the user instead of a no-op update
+			{
+				Config: testAccProviderConfig(host) + `
+resource "linux_user" "test" {
+  username    = "dave"
+  uid         = 2000
+  create_home = true
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("linux_user.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckUser(host, dave),
+					testAccCheckExists(host, "/home/dave"),
+				),
+			},
+		},
+	})
+}
+
+func testAccUserRenameConfig(host *testAccTarget, username string, home string) string {
+	return testAccProviderConfig(host) + fmt.Sprintf(`
+resource "linux_user" "test" {
+  username    = %q
+  uid         = 2100
+  home        = %q
+  create_home = true
+}
+`, username, home)
+}
+
+func TestAccUserResourceRename(t *testing.T) {
+	host := testAccHost(t)
+	erin := testAccUser{Name: "erin", Uid: 2100, Gid: 2100, Home: "/home/erin", Shell: "/bin/sh", PasswordHash: "!", MaxDays: 99999, WarnDays: 7, Expires: -1}
+	erika := erin
+	erika.Name = "erika"
+	erika.Home = "/home/erika"
+	// The primary group keeps the name of the account it was created with.
+	host.t.Cleanup(func() {
+		host.removeGroup("erin")
+	})
+	host.removeOnCleanup("/home/erin", "/home/erika")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(host),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserRenameConfig(host, "erin", "/home/erin"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_user.test", "id", "2100"),
+					testAccCheckUser(host, erin),
+				),
+			},
+			// Renaming and moving the home directory happen in place, keeping the files of the user
+			{
+				PreConfig: func() {
+					host.writeFile("/home/erin/notes", "notes\n", 0600)
+				},
+				Config: testAccUserRenameConfig(host, "erika", "/home/erika"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("linux_user.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("linux_user.test", "id", "2100"),
+					resource.TestCheckResourceAttr("linux_user.test", "username", "erika"),
+					testAccCheckUser(host, erika),
+					testAccCheckFile(host, "/home/erika/notes", "notes\n", 0600, 0),
+				),
+			},
+			// After a change of uid outside of Terraform, the account that took over the former uid is not mistaken
+			// for the user, which is still found by username
+			{
+				PreConfig: func() {
+					host.mustRun("usermod", "--uid", "2150", "--", "erika")
+					host.addUser("mallory", 2100)
+				},
+				Config: testAccUserRenameConfig(host, "erika", "/home/erika"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("linux_user.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ExpectError: regexp.MustCompile("already exists"),
+			},
+			{
+				PreConfig: func() {
+					host.removeUser("mallory")
+				},
+				Config: testAccUserRenameConfig(host, "erika", "/home/erika"),
+				Check:  testAccCheckUser(host, erika),
+			},
+			// Another account that took over the uid is not mistaken for the user, which is gone
+			{
+				PreConfig: func() {
+					host.removeUser("erika")
+					host.addUser("mallory", 2100)
+				},
+				Config: testAccUserRenameConfig(host, "erika", "/home/erika"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("linux_user.test", plancheck.ResourceActionCreate),
+					},
+				},
+				ExpectError: regexp.MustCompile("not unique"),
+			},
+		},
+	})
+}
+
+func TestUserResourceUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	server, err := testAccProtoV6ProviderFactories["linux"]()
+	assert.NilError(t, err)
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	assert.NilError(t, err)
+	userSchema := schemaResp.ResourceSchemas["linux_user"]
+	assert.Equal(t, int64(1), userSchema.Version)
+
+	// State of version 0, as written before id and the attributes of the account were added.
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "linux_user",
+		Version:  0,
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{"username":"alice","uid":1500,"gid":1500}`),
+		},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(resp.Diagnostics))
+
+	upgraded, err := resp.UpgradedState.Unmarshal(userSchema.ValueType())
+	assert.NilError(t, err)
+	attributes := map[string]tftypes.Value{}
+	assert.NilError(t, upgraded.As(&attributes))
+
+	var id, username string
+	assert.NilError(t, attributes["id"].As(&id))
+	assert.NilError(t, attributes["username"].As(&username))
+	assert.Equal(t, "1500", id)
+	assert.Equal(t, "alice", username)
+
+	// Attributes added since are read back on the next refresh, except those that only apply at creation.
+	var system bool
+	assert.NilError(t, attributes["system"].As(&system))
+	assert.Assert(t, !system)
+	assert.Assert(t, attributes["home"].IsNull())
+	assert.Assert(t, attributes["password_hash"].IsNull())
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func testAccUsersDataSourceConfig(host *testAccTarget) string {
+	return testAccProviderConfig(host) + `
+resource "linux_user" "alice" {
+  username = "alice"
+  uid      = 1500
+  shell    = "/bin/bash"
+  groups   = [linux_group.developers.name]
+}
+
+resource "linux_user" "deploy" {
+  username = "deploy"
+  system   = true
+  shell    = "/usr/sbin/nologin"
+}
+
+resource "linux_group" "developers" {
+  name = "developers"
+}
+
+data "linux_users" "human" {
+  system     = false
+  min_uid    = 1500
+  depends_on = [linux_user.alice, linux_user.deploy]
+}
+
+data "linux_users" "login" {
+  shells     = ["/bin/bash", "/bin/sh"]
+  min_uid    = 1
+  depends_on = [linux_user.alice, linux_user.deploy]
+}
+
+data "linux_users" "developers" {
+  group      = linux_group.developers.name
+  depends_on = [linux_user.alice, linux_user.deploy]
+}
+
+data "linux_users" "all" {
+  depends_on = [linux_user.alice, linux_user.deploy]
+}
+
+data "linux_user" "alice" {
+  username = linux_user.alice.username
+}
+
+data "linux_user" "deploy" {
+  username = linux_user.deploy.username
+}
+`
+}
+
+// testAccCheckUsernames verifies which accounts a linux_users data source lists, ignoring the other accounts
+// of the host.
+func testAccCheckUsernames(name string, included []string, excluded []string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		resourceState, ok := state.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found in state", name)
+		}
+
+		listed := map[string]bool{}
+		for key, value := range resourceState.Primary.Attributes {
+			var index int
+			if n, _ := fmt.Sscanf(key, "users.%d.username", &index); n == 1 {
+				listed[value] = true
+			}
+		}
+		for _, username := range included {
+			if !listed[username] {
+				return fmt.Errorf("expected %s to list %s", name, username)
+			}
+		}
+		for _, username := range excluded {
+			if listed[username] {
+				return fmt.Errorf("expected %s not to list %s", name, username)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccUsersDataSource(t *testing.T) {
+	host := testAccHost(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckUserDestroy(host),
+			testAccCheckGroupDestroy(host),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUsersDataSourceConfig(host),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.linux_users.human", "users.#", "1"),
+					resource.TestCheckResourceAttr("data.linux_users.human", "users.0.username", "alice"),
+					resource.TestCheckResourceAttr("data.linux_users.human", "users.0.uid", "1500"),
+					resource.TestCheckResourceAttr("data.linux_users.human", "users.0.shell", "/bin/bash"),
+					resource.TestCheckResourceAttr("data.linux_users.human", "users.0.system", "false"),
+					resource.TestCheckTypeSetElemAttr("data.linux_users.human", "users.0.groups.*", "developers"),
+					testAccCheckUsernames("data.linux_users.login", []string{"alice"}, []string{"root", "deploy"}),
+					resource.TestCheckResourceAttr("data.linux_users.developers", "users.#", "1"),
+					resource.TestCheckResourceAttr("data.linux_users.developers", "users.0.username", "alice"),
+					testAccCheckUsernames("data.linux_users.all", []string{"root", "alice", "deploy"}, nil),
+					resource.TestCheckResourceAttr("data.linux_users.all", "users.0.username", "root"),
+					resource.TestCheckResourceAttr("data.linux_users.all", "users.0.system", "true"),
+					resource.TestCheckResourceAttr("data.linux_user.alice", "system", "false"),
+					resource.TestCheckResourceAttr("data.linux_user.deploy", "system", "true"),
+				),
+			},
+		},
+	})
+}
@@ -1,26 +1,52 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"terraform-provider-linux/internal/util"
 	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// dateLayout is the format of dates such as expires, accepted by chage.
+const dateLayout = "2006-01-02"
+
 type LinuxUser struct {
 	Username string
 	Uid      int64
 	Gid      int64
+	Comment  string
+	Home     string
+	Shell    string
+	Groups   []string
 }
 
 type LinuxUserModel struct {
 	Username types.String `tfsdk:"username"`
 	Uid      types.Int64  `tfsdk:"uid"`
 	Gid      types.Int64  `tfsdk:"gid"`
+	Comment  types.String `tfsdk:"comment"`
+	Home     types.String `tfsdk:"home"`
+	Shell    types.String `tfsdk:"shell"`
+	Groups   types.Set    `tfsdk:"groups"`
+	System   types.Bool   `tfsdk:"system"`
+}
+
+func newGroupsValue(groups []string) types.Set {
+	elements := []attr.Value{}
+	for _, group := range groups {
+		elements = append(elements, types.StringValue(group))
+	}
+	return types.SetValueMust(types.StringType, elements)
 }
 
 func NewLinuxUserModel(user *LinuxUser) LinuxUserModel {
@@ -28,9 +54,176 @@ func NewLinuxUserModel(user *LinuxUser) LinuxUserModel {
 		Username: types.StringValue(user.Username),
 		Uid:      types.Int64Value(user.Uid),
 		Gid:      types.Int64Value(user.Gid),
+		Comment:  types.StringValue(user.Comment),
+		Home:     types.StringValue(user.Home),
+		Shell:    types.StringValue(user.Shell),
+		Groups:   newGroupsValue(user.Groups),
+		System:   types.BoolValue(isSystemUser(user.Uid)),
+	}
+}
+
+type LinuxUsersModel struct {
+	MinUid types.Int64      `tfsdk:"min_uid"`
+	MaxUid types.Int64      `tfsdk:"max_uid"`
+	Shells types.Set        `tfsdk:"shells"`
+	System types.Bool       `tfsdk:"system"`
+	Group  types.String     `tfsdk:"group"`
+	Users  []LinuxUserModel `tfsdk:"users"`
+}
+
+// filter returns the filter of the model, where null attributes match every account.
+func (m *LinuxUsersModel) filter(ctx context.Context) (*UserFilter, diag.Diagnostics) {
+	filter := &UserFilter{}
+	if !m.MinUid.IsNull() {
+		minUid := m.MinUid.ValueInt64()
+		filter.MinUid = &minUid
+	}
+	if !m.MaxUid.IsNull() {
+		maxUid := m.MaxUid.ValueInt64()
+		filter.MaxUid = &maxUid
+	}
+	if !m.Shells.IsNull() {
+		filter.Shells = []string{}
+		diags := m.Shells.ElementsAs(ctx, &filter.Shells, false)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+	if !m.System.IsNull() {
+		system := m.System.ValueBool()
+		filter.System = &system
+	}
+	filter.Group = m.Group.ValueString()
+	return filter, nil
+}
+
+// LinuxShadow is the password and aging information of an account from /etc/shadow.
+type LinuxShadow struct {
+	PasswordHash string
+	Locked       bool
+	// Expires is the date the account expires on as YYYY-MM-DD, or empty when it never expires.
+	Expires string
+	// MinDays, MaxDays and WarnDays are -1 when disabled.
+	MinDays  int64
+	MaxDays  int64
+	WarnDays int64
+}
+
+type LinuxUserResourceModel struct {
+	Id               types.String `tfsdk:"id"`
+	Username         types.String `tfsdk:"username"`
+	Uid              types.Int64  `tfsdk:"uid"`
+	Gid              types.Int64  `tfsdk:"gid"`
+	Comment          types.String `tfsdk:"comment"`
+	Home             types.String `tfsdk:"home"`
+	Shell            types.String `tfsdk:"shell"`
+	Groups           types.Set    `tfsdk:"groups"`
+	System           types.Bool   `tfsdk:"system"`
+	CreateHome       types.Bool   `tfsdk:"create_home"`
+	PasswordHash     types.String `tfsdk:"password_hash"`
+	Locked           types.Bool   `tfsdk:"locked"`
+	Expires          types.String `tfsdk:"expires"`
+	PasswordMaxDays  types.Int64  `tfsdk:"password_max_days"`
+	PasswordMinDays  types.Int64  `tfsdk:"password_min_days"`
+	PasswordWarnDays types.Int64  `tfsdk:"password_warn_days"`
+}
+
+// applyLinuxUser updates the model with the account on the server.
+// system and create_home only apply at creation and are kept, or default to false after import.
+// id is kept once set, so that it does not follow changes of uid.
+func (m *LinuxUserResourceModel) applyLinuxUser(user *LinuxUser) {
+	if m.System.IsNull() || m.System.IsUnknown() {
+		m.System = types.BoolValue(false)
+	}
+	if m.CreateHome.IsNull() || m.CreateHome.IsUnknown() {
+		m.CreateHome = types.BoolValue(false)
+	}
+
+	model := NewLinuxUserModel(user)
+	if m.Id.IsNull() || m.Id.IsUnknown() {
+		m.Id = types.StringValue(strconv.FormatInt(user.Uid, 10))
 	}
+	m.Username = model.Username
+	m.Uid = model.Uid
+	m.Gid = model.Gid
+	m.Comment = model.Comment
+	m.Home = model.Home
+	m.Shell = model.Shell
+	m.Groups = model.Groups
 }
 
+// applyLinuxShadow updates the model with the password and aging information on the server.
+// The hash is never read into the state: when it no longer matches the configured one, it is
+// cleared so that the difference is planned without revealing the hash on the server.
+func (m *LinuxUserResourceModel) applyLinuxShadow(shadow *LinuxShadow) {
+	if !m.PasswordHash.IsNull() && m.PasswordHash.ValueString() != shadow.PasswordHash {
+		m.PasswordHash = types.StringNull()
+	}
+	m.Locked = types.BoolValue(shadow.Locked)
+	m.Expires = types.StringValue(shadow.Expires)
+	m.PasswordMaxDays = types.Int64Value(shadow.MaxDays)
+	m.PasswordMinDays = types.Int64Value(shadow.MinDays)
+	m.PasswordWarnDays = types.Int64Value(shadow.WarnDays)
+}
+
+// agingOptions returns the options of chage for the expiry and aging attributes known in the plan.
+func (m *LinuxUserResourceModel) agingOptions() []string {
+	argv := []string{}
+
+	if !m.Expires.IsUnknown() && !m.Expires.IsNull() {
+		expires := m.Expires.ValueString()
+		if expires == "" {
+			expires = "-1"
+		}
+		argv = append(argv, "--expiredate", expires)
+	}
+	if !m.PasswordMinDays.IsUnknown() && !m.PasswordMinDays.IsNull() {
+		argv = append(argv, "--mindays", fmt.Sprintf("%d", m.PasswordMinDays.ValueInt64()))
+	}
+	if !m.PasswordMaxDays.IsUnknown() && !m.PasswordMaxDays.IsNull() {
+		argv = append(argv, "--maxdays", fmt.Sprintf("%d", m.PasswordMaxDays.ValueInt64()))
+	}
+	if !m.PasswordWarnDays.IsUnknown() && !m.PasswordWarnDays.IsNull() {
+		argv = append(argv, "--warndays", fmt.Sprintf("%d", m.PasswordWarnDays.ValueInt64()))
+	}
+
+	return argv
+}
+
+// commandOptions returns the options shared by useradd and usermod for the attributes known in the plan.
+func (m *LinuxUserResourceModel) commandOptions(ctx context.Context) ([]string, diag.Diagnostics) {
+	argv := []string{}
+
+	if !m.Uid.IsUnknown() && !m.Uid.IsNull() {
+		argv = append(argv, "--uid", fmt.Sprintf("%d", m.Uid.ValueInt64()))
+	}
+	if !m.Gid.IsUnknown() && !m.Gid.IsNull() {
+		argv = append(argv, "--gid", fmt.Sprintf("%d", m.Gid.ValueInt64()))
+	}
+	if !m.Comment.IsUnknown() && !m.Comment.IsNull() {
+		argv = append(argv, "--comment", m.Comment.ValueString())
+	}
+	if !m.Home.IsUnknown() && !m.Home.IsNull() {
+		// useradd spells the long option --home-dir and usermod --home.
+		argv = append(argv, "-d", m.Home.ValueString())
+	}
+	if !m.Shell.IsUnknown() && !m.Shell.IsNull() {
+		argv = append(argv, "--shell", m.Shell.ValueString())
+	}
+	if !m.Groups.IsUnknown() && !m.Groups.IsNull() {
+		groups := []string{}
+		diags := m.Groups.ElementsAs(ctx, &groups, false)
+		if diags.HasError() {
+			return nil, diags
+		}
+		sort.Strings(groups)
+		argv = append(argv, "--groups", strings.Join(groups, ","))
+	}
+
+	return argv, nil
+}
+
+// Get returns the account named username, which may also be a numeric uid, or nil if it does not exist.
 func Get(linuxCtx util.LinuxContext, username string) (*LinuxUser, *util.CommonError) {
 	if username == "" {
 		diagnostic := diag.NewErrorDiagnostic("Empty username", "Please specify username")
@@ -40,47 +233,335 @@ func Get(linuxCtx util.LinuxContext, username string) (*LinuxUser, *util.CommonE
 		}
 	}
 
-	errorhandler := func(out []byte, err error) (util.Status, *util.CommonError) {
-		if err != nil {
-			switch err.Error() {
-			case "Process exited with status 2":
-				return util.Success, nil
-			}
+	errorhandler := func(result *util.CommandResult, err error) (util.Status, *util.CommonError) {
+		// getent exits with 2 when the key is not found.
+		if result.Exited(2) {
+			return util.Success, nil
 		}
 
 		return util.Bottom, nil
 	}
-	_, stdout, commonError := sshUtil.RunCommand(linuxCtx, "getent passwd"+" "+username, errorhandler)
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("getent", "passwd", username), errorhandler)
 	if commonError != nil {
 		return nil, commonError
 	}
 
-	getent := strings.Split(stdout, ":")
-	if len(getent) != 7 {
+	if strings.TrimSpace(result.Stdout) == "" {
 		return nil, nil
 	}
-
-	uid, err := strconv.ParseInt(getent[2], 10, 64)
+	user, err := parsePasswd(strings.TrimSuffix(result.Stdout, "\n"))
 	if err != nil {
-		diagnostic := diag.NewErrorDiagnostic("Failed to parse getent uid", fmt.Sprint(err.Error()))
+		diagnostic := diag.NewErrorDiagnostic("Failed to parse getent passwd", err.Error())
 		return nil, &util.CommonError{
 			Error:       err,
 			Diagnostics: diag.Diagnostics{diagnostic},
 		}
 	}
 
+	user.Groups, commonError = getGroups(linuxCtx, user.Username)
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	return user, nil
+}
+
+// parsePasswd parses an entry of the passwd database. Groups are left empty.
+func parsePasswd(line string) (*LinuxUser, error) {
+	getent := strings.Split(line, ":")
+	if len(getent) != 7 {
+		return nil, fmt.Errorf("invalid passwd entry \"%s\"", line)
+	}
+
+	uid, err := strconv.ParseInt(getent[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid of \"%s\": %v", getent[0], err)
+	}
+
 	gid, err := strconv.ParseInt(getent[3], 10, 64)
 	if err != nil {
-		diagnostic := diag.NewErrorDiagnostic("Failed to parse getent gid", fmt.Sprint(err.Error()))
-		return nil, &util.CommonError{
-			Error:       err,
-			Diagnostics: diag.Diagnostics{diagnostic},
-		}
+		return nil, fmt.Errorf("invalid gid of \"%s\": %v", getent[0], err)
 	}
 
 	return &LinuxUser{
-		Username: username,
+		Username: getent[0],
 		Uid:      uid,
 		Gid:      gid,
+		Comment:  getent[4],
+		Home:     getent[5],
+		Shell:    getent[6],
+		Groups:   []string{},
 	}, nil
 }
+
+// parseGroups returns the supplementary groups from the output of "id -Gn", which lists the primary group first.
+func parseGroups(content string) []string {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return []string{}
+	}
+
+	groups := []string{}
+	for _, group := range fields[1:] {
+		if group != fields[0] {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func getGroups(linuxCtx util.LinuxContext, username string) ([]string, *util.CommonError) {
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("id", "-Gn", "--", username), nil)
+	if commonError != nil {
+		return nil, commonError
+	}
+	return parseGroups(result.Stdout), nil
+}
+
+// systemUidMax is the highest uid of system accounts, below the UID_MIN of 1000 used by most distributions.
+const systemUidMax = 999
+
+// nobodyUid is the overflow uid of "nobody", which is a system account despite its high uid.
+const nobodyUid = 65534
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isSystemUser(uid int64) bool {
+	return uid <= systemUidMax || uid == nobodyUid
+}
+
+// UserFilter selects accounts in List. Unset fields match every account.
+type UserFilter struct {
+	MinUid *int64
+	MaxUid *int64
+	Shells []string
+	System *bool
+	// Group matches accounts having the group as primary or supplementary group.
+	Group string
+}
+
+func (f *UserFilter) matches(user *LinuxUser, primaryGroup string) bool {
+	if f.MinUid != nil && user.Uid < *f.MinUid {
+		return false
+	}
+	if f.MaxUid != nil && user.Uid > *f.MaxUid {
+		return false
+	}
+	if f.Shells != nil && !contains(f.Shells, user.Shell) {
+		return false
+	}
+	if f.System != nil && isSystemUser(user.Uid) != *f.System {
+		return false
+	}
+	if f.Group != "" && primaryGroup != f.Group && !contains(user.Groups, f.Group) {
+		return false
+	}
+	return true
+}
+
+// parseUsers returns the accounts of the passwd database matching filter, with their supplementary groups
+// from the group database, in the order of the passwd database.
+func parseUsers(passwd string, group string, filter *UserFilter) ([]*LinuxUser, error) {
+	groupNames := map[int64]string{}
+	memberships := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSuffix(group, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		getent := strings.Split(line, ":")
+		if len(getent) != 4 {
+			return nil, fmt.Errorf("invalid group entry \"%s\"", line)
+		}
+		gid, err := strconv.ParseInt(getent[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gid of group \"%s\": %v", getent[0], err)
+		}
+		groupNames[gid] = getent[0]
+		for _, member := range strings.Split(getent[3], ",") {
+			if member != "" {
+				memberships[member] = append(memberships[member], getent[0])
+			}
+		}
+	}
+
+	users := []*LinuxUser{}
+	for _, line := range strings.Split(strings.TrimSuffix(passwd, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		user, err := parsePasswd(line)
+		if err != nil {
+			return nil, err
+		}
+
+		// As with "id -Gn", the primary group is not a supplementary group even when listed as member.
+		primaryGroup := groupNames[user.Gid]
+		for _, name := range memberships[user.Username] {
+			if name != primaryGroup && !contains(user.Groups, name) {
+				user.Groups = append(user.Groups, name)
+			}
+		}
+		sort.Strings(user.Groups)
+
+		if filter.matches(user, primaryGroup) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// List returns every account of the server matching filter.
+func List(linuxCtx util.LinuxContext, filter *UserFilter) ([]*LinuxUser, *util.CommonError) {
+	_, passwd, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("getent", "passwd"), nil)
+	if commonError != nil {
+		return nil, commonError
+	}
+	_, group, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("getent", "group"), nil)
+	if commonError != nil {
+		return nil, commonError
+	}
+
+	users, err := parseUsers(passwd.Stdout, group.Stdout, filter)
+	if err != nil {
+		return nil, &util.CommonError{
+			Error: err,
+			Diagnostics: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed to parse getent", err.Error()),
+			},
+		}
+	}
+	return users, nil
+}
+
+// parseShadowDays parses a number of days of /etc/shadow, which is empty when disabled.
+func parseShadowDays(field string) (int64, error) {
+	if field == "" {
+		return -1, nil
+	}
+	return strconv.ParseInt(field, 10, 64)
+}
+
+// parseShadow parses the output of "getent shadow".
+func parseShadow(content string) (*LinuxShadow, error) {
+	fields := strings.Split(strings.TrimSuffix(content, "\n"), ":")
+	if len(fields) != 9 {
+		return nil, errors.New("Invalid shadow entry")
+	}
+
+	shadow := &LinuxShadow{
+		PasswordHash: strings.TrimPrefix(fields[1], "!"),
+		Locked:       strings.HasPrefix(fields[1], "!"),
+	}
+
+	var err error
+	if shadow.MinDays, err = parseShadowDays(fields[3]); err != nil {
+		return nil, err
+	}
+	if shadow.MaxDays, err = parseShadowDays(fields[4]); err != nil {
+		return nil, err
+	}
+	if shadow.WarnDays, err = parseShadowDays(fields[5]); err != nil {
+		return nil, err
+	}
+
+	expires, err := parseShadowDays(fields[7])
+	if err != nil {
+		return nil, err
+	}
+	if expires >= 0 {
+		shadow.Expires = time.Unix(expires*24*60*60, 0).UTC().Format(dateLayout)
+	}
+
+	return shadow, nil
+}
+
+// GetShadow returns the password and aging information of username, or nil if it has none.
+// Reading /etc/shadow requires root.
+func GetShadow(linuxCtx util.LinuxContext, username string) (*LinuxShadow, *util.CommonError) {
+	errorhandler := func(result *util.CommandResult, err error) (util.Status, *util.CommonError) {
+		// getent exits with 2 when the key is not found.
+		if result.Exited(2) {
+			return util.Success, nil
+		}
+
+		return util.Bottom, nil
+	}
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("getent", "shadow", username), errorhandler)
+	if commonError != nil {
+		return nil, commonError
+	}
+	if result.Stdout == "" {
+		return nil, nil
+	}
+
+	shadow, err := parseShadow(result.Stdout)
+	if err != nil {
+		// The entry holds the password hash, so it is left out of the diagnostic.
+		diagnostic := diag.NewErrorDiagnostic("Failed to parse getent shadow", fmt.Sprintf("Failed to parse shadow entry of user \"%s\": %v", username, err))
+		return nil, &util.CommonError{
+			Error:       err,
+			Diagnostics: diag.Diagnostics{diagnostic},
+		}
+	}
+	return shadow, nil
+}
+
+// SetShadow sets the password hash, lock and aging of the plan that are known.
+// The hash is fed to chpasswd on stdin, so that it never appears in a command line or a log.
+func SetShadow(linuxCtx util.LinuxContext, plan *LinuxUserResourceModel, setPassword bool) *util.CommonError {
+	username := plan.Username.ValueString()
+
+	if setPassword && !plan.PasswordHash.IsUnknown() && !plan.PasswordHash.IsNull() {
+		input := username + ":" + plan.PasswordHash.ValueString() + "\n"
+		_, _, commonError := sshUtil.RunCommandWithStdin(linuxCtx, sshUtil.Command("chpasswd", "-e"), input, nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+
+	if !plan.Locked.IsUnknown() && !plan.Locked.IsNull() {
+		lock := "--unlock"
+		if plan.Locked.ValueBool() {
+			lock = "--lock"
+		} else {
+			// usermod refuses to unlock an account without a hash, yet exits with 0.
+			shadow, commonError := GetShadow(linuxCtx, username)
+			if commonError != nil {
+				return commonError
+			}
+			if shadow != nil && shadow.Locked && shadow.PasswordHash == "" {
+				diagnostic := diag.NewAttributeErrorDiagnostic(
+					path.Root("locked"),
+					"Cannot unlock user without password",
+					fmt.Sprintf("User \"%s\" has no password hash, so unlocking it would leave a passwordless account. Set password_hash to unlock it", username),
+				)
+				return &util.CommonError{
+					Diagnostics: diag.Diagnostics{diagnostic},
+				}
+			}
+		}
+		_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command("usermod", lock, username), nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+
+	if options := plan.agingOptions(); len(options) > 0 {
+		argv := append([]string{"chage"}, options...)
+		argv = append(argv, "--", username)
+		_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+
+	return nil
+}
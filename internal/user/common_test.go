@@ -1,9 +1,11 @@
 package user
 
 import (
-	"terraform-provider-linux/internal/util"
+	"strings"
+	"terraform-provider-linux/internal/util/sshtest"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
 )
@@ -13,9 +15,16 @@ func TestGet(t *testing.T) {
 		Username: "root",
 		Uid:      0,
 		Gid:      0,
+		Comment:  "root",
+		Home:     "/root",
+		Shell:    "/bin/sh",
+		Groups:   []string{"adm", "wheel"},
 	}
 
-	linuxContext := util.GetLinuxContextForTest(t)
+	server := sshtest.NewServer(t).
+		Handle("getent passwd root", sshtest.Response{Stdout: "root:x:0:0:root:/root:/bin/sh\n"}).
+		Handle("id -Gn -- root", sshtest.Response{Stdout: "root wheel adm\n"})
+	linuxContext := server.LinuxContext(t)
 	username := "root"
 	user, err := Get(linuxContext, username)
 
@@ -27,10 +36,142 @@ func TestGet(t *testing.T) {
 }
 
 func TestGetInvalidUser(t *testing.T) {
-	linuxContext := util.GetLinuxContextForTest(t)
+	// getent exits with 2 when the user does not exist.
+	server := sshtest.NewServer(t).Handle("getent passwd user_not_exists", sshtest.Response{ExitCode: 2})
+	linuxContext := server.LinuxContext(t)
 	username := "user_not_exists"
 	user, err := Get(linuxContext, username)
 
 	assert.Assert(t, is.Nil(user))
 	assert.Assert(t, is.Nil(err))
 }
+
+func TestParseGroups(t *testing.T) {
+	assert.DeepEqual(t, []string{}, parseGroups("alice\n"))
+	assert.DeepEqual(t, []string{"docker", "wheel"}, parseGroups("alice wheel docker alice\n"))
+	assert.DeepEqual(t, []string{}, parseGroups(""))
+}
+
+func TestParseShadow(t *testing.T) {
+	shadow, err := parseShadow("alice:!$6$salt$hash:19000:0:99999:7::19723:\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &LinuxShadow{
+		PasswordHash: "$6$salt$hash",
+		Locked:       true,
+		Expires:      "2024-01-01",
+		MinDays:      0,
+		MaxDays:      99999,
+		WarnDays:     7,
+	}, shadow)
+
+	shadow, err = parseShadow("bob:*:19000::::::\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &LinuxShadow{
+		PasswordHash: "*",
+		MinDays:      -1,
+		MaxDays:      -1,
+		WarnDays:     -1,
+	}, shadow)
+
+	_, err = parseShadow("bob:*\n")
+	assert.ErrorContains(t, err, "Invalid shadow entry")
+}
+
+func TestParseUsers(t *testing.T) {
+	passwd := "root:x:0:0:root:/root:/bin/bash\n" +
+		"nobody:x:65534:65534:nobody:/nonexistent:/usr/sbin/nologin\n" +
+		"alice:x:1000:1000:Alice:/home/alice:/bin/bash\n" +
+		"bob:x:1001:100:Bob:/home/bob:/bin/zsh\n"
+	group := "root:x:0:\nusers:x:100:\nalice:x:1000:\nwheel:x:10:alice,bob\ndocker:x:999:alice\nnogroup:x:65534:\n"
+	usernames := func(users []*LinuxUser) []string {
+		result := []string{}
+		for _, user := range users {
+			result = append(result, user.Username)
+		}
+		return result
+	}
+
+	users, err := parseUsers(passwd, group, &UserFilter{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"root", "nobody", "alice", "bob"}, usernames(users))
+	assert.DeepEqual(t, &LinuxUser{
+		Username: "alice",
+		Uid:      1000,
+		Gid:      1000,
+		Comment:  "Alice",
+		Home:     "/home/alice",
+		Shell:    "/bin/bash",
+		Groups:   []string{"docker", "wheel"},
+	}, users[2])
+
+	minUid, maxUid := int64(1), int64(1000)
+	users, err = parseUsers(passwd, group, &UserFilter{MinUid: &minUid, MaxUid: &maxUid})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"alice"}, usernames(users))
+
+	system := true
+	users, err = parseUsers(passwd, group, &UserFilter{System: &system})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"root", "nobody"}, usernames(users))
+
+	users, err = parseUsers(passwd, group, &UserFilter{Shells: []string{"/bin/bash"}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"root", "alice"}, usernames(users))
+
+	// Groups match both primary and supplementary groups.
+	users, err = parseUsers(passwd, group, &UserFilter{Group: "users"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"bob"}, usernames(users))
+	users, err = parseUsers(passwd, group, &UserFilter{Group: "wheel"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"alice", "bob"}, usernames(users))
+
+	_, err = parseUsers("alice:x:invalid:1000:Alice:/home/alice:/bin/bash\n", group, &UserFilter{})
+	assert.ErrorContains(t, err, "invalid uid")
+}
+
+func TestSetShadow(t *testing.T) {
+	passwordHash := "$6$salt$hash"
+	stdin := ""
+	server := sshtest.NewServer(t).
+		HandleFunc(func(command string, input string) (sshtest.Response, bool) {
+			if command != "chpasswd -e" {
+				return sshtest.Response{}, false
+			}
+			stdin = input
+			return sshtest.Response{}, true
+		}).
+		Handle("getent shadow carol", sshtest.Response{Stdout: "carol:" + passwordHash + ":19000:0:99999:7:::\n"}).
+		Handle("usermod --unlock carol", sshtest.Response{}).
+		Handle("chage --maxdays 90 -- carol", sshtest.Response{})
+
+	plan := &LinuxUserResourceModel{
+		Username:        types.StringValue("carol"),
+		PasswordHash:    types.StringValue(passwordHash),
+		Locked:          types.BoolValue(false),
+		PasswordMaxDays: types.Int64Value(90),
+	}
+	err := SetShadow(server.LinuxContext(t), plan, true)
+	assert.Assert(t, is.Nil(err))
+
+	// The hash is fed to chpasswd on stdin, so it never shows in the process list.
+	assert.Equal(t, "carol:"+passwordHash+"\n", stdin)
+	for _, command := range server.Commands() {
+		assert.Assert(t, !strings.Contains(command, passwordHash), command)
+	}
+}
+
+func TestSetShadowUnlockWithoutPassword(t *testing.T) {
+	server := sshtest.NewServer(t).
+		Handle("getent shadow carol", sshtest.Response{Stdout: "carol:!:19000:0:99999:7:::\n"})
+
+	plan := &LinuxUserResourceModel{
+		Username: types.StringValue("carol"),
+		Locked:   types.BoolValue(false),
+	}
+	err := SetShadow(server.LinuxContext(t), plan, false)
+
+	assert.Assert(t, err != nil)
+	assert.Equal(t, "Cannot unlock user without password", err.Diagnostics[0].Summary())
+	assert.DeepEqual(t, []string{"getent shadow carol"}, server.Commands())
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
@@ -38,6 +39,27 @@ func (d *userDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 			"gid": schema.Int64Attribute{
 				Computed: true,
 			},
+			"comment": schema.StringAttribute{
+				Description: "GECOS field of the user, usually the full name",
+				Computed:    true,
+			},
+			"home": schema.StringAttribute{
+				Description: "Home directory of the user",
+				Computed:    true,
+			},
+			"shell": schema.StringAttribute{
+				Description: "Login shell of the user",
+				Computed:    true,
+			},
+			"groups": schema.SetAttribute{
+				Description: "Supplementary groups of the user, by name",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"system": schema.BoolAttribute{
+				Description: "Whether the uid is in the system range, at most 999 or the uid of nobody",
+				Computed:    true,
+			},
 		},
 	}
 }
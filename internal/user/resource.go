@@ -3,20 +3,31 @@ package user
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"terraform-provider-linux/internal/util"
 	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
-	_ resource.Resource                = &userResource{}
-	_ resource.ResourceWithConfigure   = &userResource{}
-	_ resource.ResourceWithImportState = &userResource{}
+	_ resource.Resource                   = &userResource{}
+	_ resource.ResourceWithConfigure      = &userResource{}
+	_ resource.ResourceWithImportState    = &userResource{}
+	_ resource.ResourceWithValidateConfig = &userResource{}
+	_ resource.ResourceWithModifyPlan     = &userResource{}
+	_ resource.ResourceWithUpgradeState   = &userResource{}
 )
 
 func NewUserResource() resource.Resource {
@@ -33,27 +44,190 @@ func (r *userResource) Metadata(_ context.Context, req resource.MetadataRequest,
 
 func (r *userResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
-			"username": schema.StringAttribute{
-				Required: true,
+			"id": schema.StringAttribute{
+				Description: "Uid the user was created with. It identifies the resource and is kept across renames and changes of uid",
+				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"username": schema.StringAttribute{
+				Description: "Name of the user. Renaming changes the name of the account in place, keeping its files",
+				Required:    true,
+			},
 			"uid": schema.Int64Attribute{
 				Computed: true,
 				Optional: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 			"gid": schema.Int64Attribute{
-				Computed: true,
-				Optional: true,
+				Description: "Primary group of the user. Defaults to a new group named after the user",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"comment": schema.StringAttribute{
+				Description: "GECOS field of the user, usually the full name",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"home": schema.StringAttribute{
+				Description: "Home directory of the user. Defaults to the `useradd` default, usually `/home/<username>`. Changing it moves the content of the previous home directory",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"shell": schema.StringAttribute{
+				Description: "Login shell of the user. Defaults to the `useradd` default",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"groups": schema.SetAttribute{
+				Description: "Supplementary groups of the user, by name. The user is removed from every other supplementary group",
+				ElementType: types.StringType,
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"system": schema.BoolAttribute{
+				Description: "Create a system account, with an uid from the system range. Only applies when the user is created, so changing it replaces the user",
+				Computed:    true,
+				Optional:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"create_home": schema.BoolAttribute{
+				Description: "Create the home directory. Only applies when the user is created, so changing it replaces the user",
+				Computed:    true,
+				Optional:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"password_hash": schema.StringAttribute{
+				Description: "Password hash of the user as found in `/etc/shadow`, such as the output of `mkpasswd -m sha-512`. The hash on the server is never read into the state, a different one is only reported as a change",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"locked": schema.BoolAttribute{
+				Description: "Lock the password of the user, so that password authentication is refused. An account without `password_hash` can not be unlocked",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"expires": schema.StringAttribute{
+				Description: "Date the account expires on as `YYYY-MM-DD`, or empty for an account that never expires",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"password_max_days": schema.Int64Attribute{
+				Description: "Maximum number of days a password is valid, or -1 to disable password expiry",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"password_min_days": schema.Int64Attribute{
+				Description: "Minimum number of days between password changes, or -1 to disable",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"password_warn_days": schema.Int64Attribute{
+				Description: "Number of days the user is warned before the password expires, or -1 to disable",
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
+func (r *userResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config LinuxUserResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.PasswordHash.IsNull() && !config.PasswordHash.IsUnknown() {
+		// The hash is fed to chpasswd as "username:hash" lines, and a leading "!" means locked.
+		passwordHash := config.PasswordHash.ValueString()
+		if strings.ContainsAny(passwordHash, ":\n") || strings.HasPrefix(passwordHash, "!") {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_hash"),
+				"Invalid password hash",
+				"Password hash can not contain \":\" or newlines, nor start with \"!\". Use `locked` to lock the password",
+			)
+		}
+	}
+
+	if !config.Expires.IsNull() && !config.Expires.IsUnknown() && config.Expires.ValueString() != "" {
+		if _, err := time.Parse(dateLayout, config.Expires.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("expires"),
+				"Invalid expiry date",
+				fmt.Sprintf("Expiry date should be formatted as YYYY-MM-DD: %v", err),
+			)
+		}
+	}
+}
+
+// ModifyPlan plans an unset locked as unknown when the hash changes.
+func (r *userResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	// chpasswd replaces the lock along with the hash, so a lock that is not configured is only known once applied.
+	var config, plan, state LinuxUserResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	diags = req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Locked.IsNull() && !plan.PasswordHash.Equal(state.PasswordHash) {
+		diags = resp.Plan.SetAttribute(ctx, path.Root("locked"), types.BoolUnknown())
+		resp.Diagnostics.Append(diags...)
+	}
+}
+
 func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan LinuxUserModel
+	var plan LinuxUserResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -62,7 +236,7 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 
 	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
 
-	command := "useradd"
+	argv := []string{"useradd"}
 
 	if plan.Username.IsUnknown() || plan.Username.IsNull() {
 		resp.Diagnostics.AddAttributeError(
@@ -82,16 +256,30 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	command = command + " " + username
+	argv = append(argv, username)
 
-	if !plan.Uid.IsUnknown() && !plan.Uid.IsNull() {
-		command = command + " " + "--uid" + " " + fmt.Sprintf("%d", plan.Uid.ValueInt64())
+	options, diags := plan.commandOptions(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !plan.Gid.IsUnknown() && !plan.Uid.IsNull() {
-		command = command + " " + "--gid" + " " + fmt.Sprintf("%d", plan.Gid.ValueInt64())
+	argv = append(argv, options...)
+	if plan.System.ValueBool() {
+		argv = append(argv, "--system")
+	}
+	if plan.CreateHome.ValueBool() {
+		argv = append(argv, "--create-home")
+	} else {
+		argv = append(argv, "--no-create-home")
+	}
+
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
 	}
 
-	_, _, commonError := sshUtil.RunCommand(linuxCtx, command, nil)
+	commonError = SetShadow(linuxCtx, &plan, true)
 	if commonError != nil {
 		resp.Diagnostics.Append(commonError.Diagnostics...)
 		return
@@ -107,7 +295,15 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	plan = NewLinuxUserModel(user)
+	plan.applyLinuxUser(user)
+	shadow, commonError := GetShadow(linuxCtx, username)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if shadow != nil {
+		plan.applyLinuxShadow(shadow)
+	}
 	diags = resp.State.Set(linuxCtx.Ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -115,28 +311,51 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 }
 
+// get returns the account of state. It is looked up by its last known uid, and only taken when it still has the
+// username and the home of state, since the uid may have been freed and taken by another account. Otherwise, as
+// after import or a change of uid outside of Terraform, the account is looked up by username.
+func (r *userResource) get(linuxCtx util.LinuxContext, state *LinuxUserResourceModel) (*LinuxUser, *util.CommonError) {
+	if !state.Uid.IsNull() && !state.Uid.IsUnknown() {
+		user, commonError := Get(linuxCtx, strconv.FormatInt(state.Uid.ValueInt64(), 10))
+		if commonError != nil {
+			return nil, commonError
+		}
+		if user != nil && user.Username == state.Username.ValueString() && user.Home == state.Home.ValueString() {
+			return user, nil
+		}
+	}
+	return Get(linuxCtx, state.Username.ValueString())
+}
+
 func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
 
-	var state LinuxUserModel
+	var state LinuxUserResourceModel
 	diags := req.State.Get(linuxCtx.Ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	user, commonError := Get(linuxCtx, state.Username.ValueString())
+	user, commonError := r.get(linuxCtx, &state)
 	if commonError != nil {
 		resp.Diagnostics.Append(commonError.Diagnostics...)
 		return
 	}
 	if user == nil {
-		// TODO: Find way to update resource if user deleted outside
-		resp.Diagnostics.AddError("User not found", "This indicates user created with terraform deleted outside")
+		resp.State.RemoveResource(linuxCtx.Ctx)
 		return
 	}
 
-	state = NewLinuxUserModel(user)
+	state.applyLinuxUser(user)
+	shadow, commonError := GetShadow(linuxCtx, user.Username)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if shadow != nil {
+		state.applyLinuxShadow(shadow)
+	}
 
 	diags = resp.State.Set(linuxCtx.Ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -148,14 +367,21 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
 
-	var plan LinuxUserModel
+	var plan LinuxUserResourceModel
 	diags := req.Plan.Get(linuxCtx.Ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	command := "usermod"
+	var state LinuxUserResourceModel
+	diags = req.State.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	argv := []string{"usermod"}
 
 	if plan.Username.IsUnknown() || plan.Username.IsNull() {
 		resp.Diagnostics.AddAttributeError(
@@ -175,15 +401,30 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	command = command + " " + username
-	if !plan.Uid.IsUnknown() && !plan.Uid.IsNull() {
-		command = command + " " + "--uid" + " " + fmt.Sprintf("%d", plan.Uid.ValueInt64())
+	// The account is renamed in place, and its previous home directory is moved to the new one.
+	if username != state.Username.ValueString() {
+		argv = append(argv, "--login", username)
 	}
-	if !plan.Gid.IsUnknown() && !plan.Uid.IsNull() {
-		command = command + " " + "--gid" + " " + fmt.Sprintf("%d", plan.Gid.ValueInt64())
+
+	options, diags := plan.commandOptions(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	argv = append(argv, options...)
+	if !plan.Home.IsUnknown() && !plan.Home.IsNull() && !plan.Home.Equal(state.Home) {
+		argv = append(argv, "--move-home")
 	}
 
-	_, _, commonError := sshUtil.RunCommand(linuxCtx, command, nil)
+	argv = append(argv, state.Username.ValueString())
+
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	commonError = SetShadow(linuxCtx, &plan, !plan.PasswordHash.Equal(state.PasswordHash))
 	if commonError != nil {
 		resp.Diagnostics.Append(commonError.Diagnostics...)
 		return
@@ -199,7 +440,15 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	plan = NewLinuxUserModel(user)
+	plan.applyLinuxUser(user)
+	shadow, commonError := GetShadow(linuxCtx, username)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+	if shadow != nil {
+		plan.applyLinuxShadow(shadow)
+	}
 	diags = resp.State.Set(linuxCtx.Ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -210,14 +459,14 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	linuxCtx := util.NewLinuxContext(ctx, r.providerData)
 
-	var state LinuxUserModel
+	var state LinuxUserResourceModel
 	diags := req.State.Get(linuxCtx.Ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	command := "userdel"
+	argv := []string{"userdel"}
 	if state.Username.IsUnknown() || state.Username.IsNull() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("username"),
@@ -236,8 +485,8 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	command = command + " " + username
-	_, _, commonError := sshUtil.RunCommand(linuxCtx, command, nil)
+	argv = append(argv, username)
+	_, _, commonError := sshUtil.RunCommand(linuxCtx, sshUtil.Command(argv...), nil)
 	if commonError != nil {
 		resp.Diagnostics.Append(commonError.Diagnostics...)
 		return
@@ -260,3 +509,63 @@ func (r *userResource) Configure(ctx context.Context, req resource.ConfigureRequ
 func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("username"), req, resp)
 }
+
+// linuxUserResourceModelV0 is the state of version 0, which was keyed by username and only had uid and gid.
+type linuxUserResourceModelV0 struct {
+	Username types.String `tfsdk:"username"`
+	Uid      types.Int64  `tfsdk:"uid"`
+	Gid      types.Int64  `tfsdk:"gid"`
+}
+
+func (r *userResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"username": schema.StringAttribute{Required: true},
+					"uid":      schema.Int64Attribute{Optional: true, Computed: true},
+					"gid":      schema.Int64Attribute{Optional: true, Computed: true},
+				},
+			},
+			StateUpgrader: upgradeUserStateV0,
+		},
+	}
+}
+
+// upgradeUserStateV0 derives id from the uid of the state. Without uid, the account is
+// still found by username on the next refresh, which sets id. The attributes added since are read back by that
+// refresh, except system and create_home, which only applied at creation and default to false as after import.
+func upgradeUserStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior linuxUserResourceModelV0
+	diags := req.State.Get(ctx, &prior)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := types.StringNull()
+	if !prior.Uid.IsNull() && !prior.Uid.IsUnknown() {
+		id = types.StringValue(strconv.FormatInt(prior.Uid.ValueInt64(), 10))
+	}
+
+	state := LinuxUserResourceModel{
+		Id:               id,
+		Username:         prior.Username,
+		Uid:              prior.Uid,
+		Gid:              prior.Gid,
+		Comment:          types.StringNull(),
+		Home:             types.StringNull(),
+		Shell:            types.StringNull(),
+		Groups:           types.SetNull(types.StringType),
+		System:           types.BoolValue(false),
+		CreateHome:       types.BoolValue(false),
+		PasswordHash:     types.StringNull(),
+		Locked:           types.BoolNull(),
+		Expires:          types.StringNull(),
+		PasswordMaxDays:  types.Int64Null(),
+		PasswordMinDays:  types.Int64Null(),
+		PasswordWarnDays: types.Int64Null(),
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
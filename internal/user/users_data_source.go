@@ -0,0 +1,143 @@
+package user
+
+import (
+	"context"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &usersDataSource{}
+	_ datasource.DataSourceWithConfigure = &usersDataSource{}
+)
+
+func NewUsersDataSource() datasource.DataSource {
+	return &usersDataSource{}
+}
+
+type usersDataSource struct {
+	providerData *util.LinuxProviderData
+}
+
+func (d *usersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_users"
+}
+
+func (d *usersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the accounts of the passwd database. Every filter that is set has to match",
+		Attributes: map[string]schema.Attribute{
+			"min_uid": schema.Int64Attribute{
+				Description: "Lowest uid of the listed accounts",
+				Optional:    true,
+			},
+			"max_uid": schema.Int64Attribute{
+				Description: "Highest uid of the listed accounts",
+				Optional:    true,
+			},
+			"shells": schema.SetAttribute{
+				Description: "Login shells of the listed accounts",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"system": schema.BoolAttribute{
+				Description: "List only system accounts when true, or only human accounts when false. System accounts have a uid up to 999, or are nobody",
+				Optional:    true,
+			},
+			"group": schema.StringAttribute{
+				Description: "Name of a group the listed accounts have as primary or supplementary group",
+				Optional:    true,
+			},
+			"users": schema.ListNestedAttribute{
+				Description: "Matching accounts, in the order of the passwd database",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"username": schema.StringAttribute{
+							Computed: true,
+						},
+						"uid": schema.Int64Attribute{
+							Computed: true,
+						},
+						"gid": schema.Int64Attribute{
+							Computed: true,
+						},
+						"comment": schema.StringAttribute{
+							Description: "GECOS field of the user, usually the full name",
+							Computed:    true,
+						},
+						"home": schema.StringAttribute{
+							Description: "Home directory of the user",
+							Computed:    true,
+						},
+						"shell": schema.StringAttribute{
+							Description: "Login shell of the user",
+							Computed:    true,
+						},
+						"groups": schema.SetAttribute{
+							Description: "Supplementary groups of the user, by name",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+						"system": schema.BoolAttribute{
+							Description: "Whether the uid is in the system range, at most 999 or the uid of nobody",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *usersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	linuxCtx := util.NewLinuxContext(ctx, d.providerData)
+
+	var state LinuxUsersModel
+
+	diags := req.Config.Get(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter, diags := state.filter(linuxCtx.Ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, commonError := List(linuxCtx, filter)
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	state.Users = []LinuxUserModel{}
+	for _, user := range users {
+		state.Users = append(state.Users, NewLinuxUserModel(user))
+	}
+
+	diags = resp.State.Set(linuxCtx.Ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (d *usersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	providerData, commonError := util.ConvertProviderData(req.ProviderData)
+	if providerData == nil && commonError == nil {
+		return
+	}
+	if commonError != nil {
+		resp.Diagnostics.Append(commonError.Diagnostics...)
+		return
+	}
+
+	d.providerData = providerData
+}
@@ -0,0 +1,22 @@
+package util
+
+import (
+	"time"
+)
+
+// CommandResult is the outcome of a remote command.
+type CommandResult struct {
+	// ExitCode is the exit status of the command, or -1 when the command did not report one,
+	// for example because the connection was lost.
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+	// Signal is the name of the signal that killed the command, such as "KILL", or empty.
+	Signal string
+}
+
+// Exited reports whether the command ran to completion with exitCode.
+func (r *CommandResult) Exited(exitCode int) bool {
+	return r != nil && r.Signal == "" && r.ExitCode == exitCode
+}
@@ -1,10 +1,10 @@
 package util
 
 import (
+	"context"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/melbahja/goph"
 )
 
 type MutlipleErrorContainer struct {
@@ -51,7 +51,16 @@ const (
 	Bottom  Status = -1
 )
 
-func BackoffRetry(fn func() Status, retry int) Status {
+// RetryPolicy controls how often and how fast a failed attempt is retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of attempts made after the first one.
+	MaxRetries int
+	// BaseDelay is the wait before the first retry, doubled before each following one.
+	BaseDelay time.Duration
+}
+
+// BackoffRetry calls fn until it succeeds, policy.MaxRetries retries are made or ctx is done.
+func BackoffRetry(ctx context.Context, fn func() Status, policy RetryPolicy) Status {
 	count := 0
 
 	for {
@@ -61,16 +70,37 @@ func BackoffRetry(fn func() Status, retry int) Status {
 			return Success
 		}
 
+		if count >= policy.MaxRetries {
+			return result
+		}
+
+		timer := time.NewTimer(policy.BaseDelay << count)
 		count = count + 1
-		if count >= retry {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
 			return result
+		case <-timer.C:
 		}
-		time.Sleep(time.Duration(2) * time.Second << count)
 	}
 }
 
+// Become describes how commands are elevated when the login user is not privileged.
+type Become struct {
+	// Method is one of "sudo", "doas" or "su".
+	Method   string
+	User     string
+	Password string
+}
+
 type LinuxProviderData struct {
-	SshClient *goph.Client
+	Executor Executor
+	// Become is nil when commands run as the login user.
+	Become *Become
+	// CommandTimeout bounds every remote command. Zero means commands only stop when Terraform cancels.
+	CommandTimeout time.Duration
+	// Retry applies to commands that failed because of the connection, never to failures of the command itself.
+	Retry RetryPolicy
 }
 
 func ConvertProviderData(providerData any) (*LinuxProviderData, *CommonError) {
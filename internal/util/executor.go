@@ -0,0 +1,53 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// Executor runs commands and transfers files on the managed host, hiding how the host is reached.
+// Implementations must be safe for concurrent use.
+type Executor interface {
+	// Run runs command with sh. The returned result is never nil.
+	// The error is nil when the command exited with status 0, and a *TransportError when the host could not be reached.
+	Run(ctx context.Context, command string) (*CommandResult, error)
+	// RunWithStdin is Run feeding stdin to the command.
+	RunWithStdin(ctx context.Context, command string, stdin io.Reader) (*CommandResult, error)
+	// Upload creates remotePath, which must not exist yet, with content. The file is only accessible by its owner,
+	// with mode 0600, before any content is written.
+	Upload(ctx context.Context, content io.Reader, remotePath string) error
+	// Download copies the content of remotePath into content.
+	Download(ctx context.Context, remotePath string, content io.Writer) error
+	// Stat returns information about remotePath as seen by User without elevation, or nil if it does not exist.
+	Stat(ctx context.Context, remotePath string) (*FileStat, error)
+	// User returns the name of the user commands run as before any elevation.
+	User() string
+}
+
+// FileStat describes a file on the host. Symbolic links are followed.
+type FileStat struct {
+	Mode os.FileMode
+	Uid  int64
+	Gid  int64
+	Size int64
+}
+
+// TransportError means that the host could not be reached, as opposed to a command that ran and failed.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+func IsTransportError(err error) bool {
+	var transportError *TransportError
+	return errors.As(err, &transportError)
+}
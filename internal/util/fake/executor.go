@@ -0,0 +1,190 @@
+// Package fake provides a scripted util.Executor for unit tests, so that code running commands
+// can be tested without a host.
+package fake
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"terraform-provider-linux/internal/util"
+)
+
+// Response is the scripted outcome of a command.
+type Response struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	// TransportError fails the command as if the host could not be reached.
+	TransportError error
+}
+
+// Executor answers commands from a script and keeps uploaded files in memory.
+type Executor struct {
+	mu        sync.Mutex
+	responses map[string][]Response
+	files     map[string][]byte
+	commands  []string
+	stdins    []string
+	user      string
+}
+
+var _ util.Executor = &Executor{}
+
+func NewExecutor() *Executor {
+	return &Executor{
+		responses: map[string][]Response{},
+		files:     map[string][]byte{},
+		user:      "root",
+	}
+}
+
+// On scripts the responses to command. Each run consumes one response and the last one is repeated.
+// Commands that are not scripted exit with status 127.
+func (e *Executor) On(command string, responses ...Response) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.responses[command] = append(e.responses[command], responses...)
+	return e
+}
+
+// WithFile stores content at remotePath.
+func (e *Executor) WithFile(remotePath string, content string) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.files[remotePath] = []byte(content)
+	return e
+}
+
+// WithUser sets the user returned by User.
+func (e *Executor) WithUser(user string) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.user = user
+	return e
+}
+
+// Commands returns every command run so far, in order.
+func (e *Executor) Commands() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string{}, e.commands...)
+}
+
+// Stdins returns the input fed to every command run so far, in order.
+func (e *Executor) Stdins() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string{}, e.stdins...)
+}
+
+// File returns the content stored at remotePath.
+func (e *Executor) File(remotePath string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	content, ok := e.files[remotePath]
+	return string(content), ok
+}
+
+func (e *Executor) Run(ctx context.Context, command string) (*util.CommandResult, error) {
+	return e.RunWithStdin(ctx, command, nil)
+}
+
+func (e *Executor) RunWithStdin(ctx context.Context, command string, stdin io.Reader) (*util.CommandResult, error) {
+	input := ""
+	if stdin != nil {
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return &util.CommandResult{ExitCode: -1}, err
+		}
+		input = string(content)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.commands = append(e.commands, command)
+	e.stdins = append(e.stdins, input)
+
+	response := Response{
+		ExitCode: 127,
+		Stderr:   fmt.Sprintf("sh: %s: not scripted\n", command),
+	}
+	if responses := e.responses[command]; len(responses) > 0 {
+		response = responses[0]
+		if len(responses) > 1 {
+			e.responses[command] = responses[1:]
+		}
+	}
+
+	if response.TransportError != nil {
+		return &util.CommandResult{ExitCode: -1}, &util.TransportError{Err: response.TransportError}
+	}
+
+	result := &util.CommandResult{
+		ExitCode: response.ExitCode,
+		Stdout:   response.Stdout,
+		Stderr:   response.Stderr,
+	}
+	if response.ExitCode != 0 {
+		return result, fmt.Errorf("Process exited with status %d", response.ExitCode)
+	}
+	return result, nil
+}
+
+func (e *Executor) Upload(ctx context.Context, content io.Reader, remotePath string) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.files[remotePath]; ok {
+		return &os.PathError{Op: "open", Path: remotePath, Err: os.ErrExist}
+	}
+	e.files[remotePath] = data
+	return nil
+}
+
+func (e *Executor) Download(ctx context.Context, remotePath string, content io.Writer) error {
+	e.mu.Lock()
+	data, ok := e.files[remotePath]
+	e.mu.Unlock()
+
+	if !ok {
+		return &os.PathError{Op: "open", Path: remotePath, Err: os.ErrNotExist}
+	}
+	_, err := io.Copy(content, bytes.NewReader(data))
+	return err
+}
+
+func (e *Executor) Stat(ctx context.Context, remotePath string) (*util.FileStat, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	data, ok := e.files[remotePath]
+	if !ok {
+		return nil, nil
+	}
+	return &util.FileStat{
+		Mode: 0644,
+		Size: int64(len(data)),
+	}, nil
+}
+
+func (e *Executor) User() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.user
+}
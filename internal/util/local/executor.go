@@ -0,0 +1,171 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+	"terraform-provider-linux/internal/util"
+	"time"
+)
+
+// Executor runs commands and transfers files on the machine the provider runs on.
+type Executor struct {
+	user string
+}
+
+var _ util.Executor = &Executor{}
+
+func NewExecutor() *Executor {
+	username := strconv.Itoa(os.Getuid())
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+
+	return &Executor{
+		user: username,
+	}
+}
+
+func (e *Executor) Run(ctx context.Context, command string) (*util.CommandResult, error) {
+	return e.RunWithStdin(ctx, command, nil)
+}
+
+// RunWithStdin executes command with sh. The process is interrupted when ctx is done, and killed if it does not exit shortly after.
+func (e *Executor) RunWithStdin(ctx context.Context, command string, stdin io.Reader) (*util.CommandResult, error) {
+	start := time.Now()
+	result := &util.CommandResult{
+		ExitCode: -1,
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = 5 * time.Second
+
+	err := cmd.Run()
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.Duration = time.Since(start)
+
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	var exitError *exec.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitError):
+		result.ExitCode = exitError.ExitCode()
+		if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			result.ExitCode = 128 + int(status.Signal())
+			result.Signal = signalName(status.Signal())
+		}
+	}
+	return result, err
+}
+
+func (e *Executor) Upload(ctx context.Context, content io.Reader, remotePath string) error {
+	file, err := os.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, readerWithContext{ctx: ctx, reader: content})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (e *Executor) Download(ctx context.Context, remotePath string, content io.Writer) error {
+	file, err := os.Open(remotePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(content, readerWithContext{ctx: ctx, reader: file})
+	return err
+}
+
+func (e *Executor) Stat(ctx context.Context, remotePath string) (*util.FileStat, error) {
+	fileInfo, err := os.Stat(remotePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &util.FileStat{
+		Mode: fileInfo.Mode(),
+		Size: fileInfo.Size(),
+	}
+	if sys, ok := fileInfo.Sys().(*syscall.Stat_t); ok {
+		stat.Uid = int64(sys.Uid)
+		stat.Gid = int64(sys.Gid)
+	}
+	return stat, nil
+}
+
+func (e *Executor) User() string {
+	return e.user
+}
+
+// readerWithContext stops reading once ctx is done, which aborts a running transfer.
+type readerWithContext struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r readerWithContext) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// signalName returns the name of signal as reported by OpenSSH, such as "KILL".
+func signalName(signal syscall.Signal) string {
+	switch signal {
+	case syscall.SIGABRT:
+		return "ABRT"
+	case syscall.SIGALRM:
+		return "ALRM"
+	case syscall.SIGFPE:
+		return "FPE"
+	case syscall.SIGHUP:
+		return "HUP"
+	case syscall.SIGILL:
+		return "ILL"
+	case syscall.SIGINT:
+		return "INT"
+	case syscall.SIGKILL:
+		return "KILL"
+	case syscall.SIGPIPE:
+		return "PIPE"
+	case syscall.SIGQUIT:
+		return "QUIT"
+	case syscall.SIGSEGV:
+		return "SEGV"
+	case syscall.SIGTERM:
+		return "TERM"
+	case syscall.SIGUSR1:
+		return "USR1"
+	case syscall.SIGUSR2:
+		return "USR2"
+	}
+	return signal.String()
+}
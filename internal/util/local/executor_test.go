@@ -0,0 +1,74 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRun(t *testing.T) {
+	executor := NewExecutor()
+
+	result, err := executor.RunWithStdin(context.Background(), "cat; echo error >&2", strings.NewReader("input"))
+
+	assert.NilError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "input", result.Stdout)
+	assert.Equal(t, "error\n", result.Stderr)
+}
+
+func TestRunExitCode(t *testing.T) {
+	executor := NewExecutor()
+
+	result, err := executor.Run(context.Background(), "exit 3")
+
+	assert.ErrorContains(t, err, "exit status 3")
+	assert.Assert(t, result.Exited(3))
+}
+
+func TestRunSignal(t *testing.T) {
+	executor := NewExecutor()
+
+	result, err := executor.Run(context.Background(), "kill -KILL $$")
+
+	assert.Assert(t, err != nil)
+	assert.Equal(t, "KILL", result.Signal)
+	assert.Equal(t, 137, result.ExitCode)
+}
+
+func TestUploadDownload(t *testing.T) {
+	executor := NewExecutor()
+	remotePath := filepath.Join(t.TempDir(), "file")
+
+	err := executor.Upload(context.Background(), strings.NewReader("content"), remotePath)
+	assert.NilError(t, err)
+
+	// Upload never overwrites an existing file.
+	err = executor.Upload(context.Background(), strings.NewReader("other"), remotePath)
+	assert.Assert(t, os.IsExist(err))
+
+	var content bytes.Buffer
+	err = executor.Download(context.Background(), remotePath, &content)
+	assert.NilError(t, err)
+	assert.Equal(t, "content", content.String())
+
+	stat, err := executor.Stat(context.Background(), remotePath)
+	assert.NilError(t, err)
+	assert.Equal(t, int64(7), stat.Size)
+	assert.Equal(t, int64(os.Getuid()), stat.Uid)
+	assert.Equal(t, os.FileMode(0600), stat.Mode.Perm())
+}
+
+func TestStatNotFound(t *testing.T) {
+	executor := NewExecutor()
+
+	stat, err := executor.Stat(context.Background(), filepath.Join(t.TempDir(), "missing"))
+
+	assert.NilError(t, err)
+	assert.Assert(t, stat == nil)
+}
@@ -0,0 +1,153 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/melbahja/goph"
+)
+
+// DefaultMaxSessions matches the default MaxSessions of OpenSSH.
+const DefaultMaxSessions = 10
+
+// SessionManager shares one SSH connection between all resources Terraform runs in parallel.
+// It caps the number of concurrent sessions so that the server never rejects a channel,
+// queues the excess and redials once the connection is known to be broken.
+type SessionManager struct {
+	mu     sync.Mutex
+	client *goph.Client
+	broken bool
+	closed bool
+	// dial opens a new connection to the host. Nil disables reconnection.
+	dial func() (*goph.Client, error)
+
+	slots   chan struct{}
+	active  atomic.Int64
+	waiting atomic.Int64
+
+	acquired  atomic.Int64
+	totalWait atomic.Int64
+}
+
+// NewSessionManager allows at most maxSessions concurrent sessions on client, or DefaultMaxSessions when maxSessions is not positive.
+func NewSessionManager(client *goph.Client, dial func() (*goph.Client, error), maxSessions int) *SessionManager {
+	if maxSessions <= 0 {
+		maxSessions = DefaultMaxSessions
+	}
+
+	return &SessionManager{
+		client: client,
+		dial:   dial,
+		slots:  make(chan struct{}, maxSessions),
+	}
+}
+
+// Acquire waits for a free session slot and returns the connection to open the session on.
+// release must be called once the session is closed.
+func (m *SessionManager) Acquire(ctx context.Context) (client *goph.Client, release func(), err error) {
+	start := time.Now()
+
+	m.waiting.Add(1)
+	select {
+	case m.slots <- struct{}{}:
+		m.waiting.Add(-1)
+	case <-ctx.Done():
+		m.waiting.Add(-1)
+		return nil, nil, ctx.Err()
+	}
+	wait := time.Since(start)
+
+	client, err = m.connected(ctx)
+	if err != nil {
+		<-m.slots
+		return nil, nil, err
+	}
+
+	active := m.active.Add(1)
+	acquired := m.acquired.Add(1)
+	totalWait := time.Duration(m.totalWait.Add(int64(wait)))
+
+	fields := map[string]interface{}{
+		"wait":         wait.String(),
+		"average_wait": (totalWait / time.Duration(acquired)).String(),
+		"active":       active,
+		"waiting":      m.waiting.Load(),
+		"max_sessions": cap(m.slots),
+	}
+	if wait >= time.Second {
+		tflog.Info(ctx, fmt.Sprintf("Waited %s for a free SSH session, consider raising max_sessions", wait), fields)
+	} else {
+		tflog.Debug(ctx, "Acquired SSH session", fields)
+	}
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			m.active.Add(-1)
+			<-m.slots
+		})
+	}
+	return client, release, nil
+}
+
+// User returns the login user of the connection.
+func (m *SessionManager) User() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.client.User()
+}
+
+// MarkBroken records that client lost its connection, so that the next Acquire redials.
+// It has no effect when client was already replaced.
+func (m *SessionManager) MarkBroken(client *goph.Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == client {
+		m.broken = true
+	}
+}
+
+// Close closes the connection. Acquire fails afterwards instead of redialing.
+func (m *SessionManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	return m.client.Close()
+}
+
+func (m *SessionManager) connected(ctx context.Context) (*goph.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, errors.New("connection is closed")
+	}
+	if !m.broken {
+		return m.client, nil
+	}
+	if m.dial == nil {
+		return nil, errors.New("connection is broken and reconnection is not supported")
+	}
+
+	tflog.Info(ctx, "Reconnecting to host")
+	client, err := m.dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconnect: %w", err)
+	}
+	m.client.Close()
+	m.client = client
+	m.broken = false
+
+	return client, nil
+}
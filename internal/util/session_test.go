@@ -0,0 +1,39 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestSessionManagerQueuesExcessSessions(t *testing.T) {
+	sessions := NewSessionManager(nil, nil, 1)
+
+	_, release, err := sessions.Acquire(context.Background())
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = sessions.Acquire(ctx)
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+
+	release()
+	release()
+	_, release, err = sessions.Acquire(context.Background())
+	assert.NilError(t, err)
+	release()
+}
+
+func TestSessionManagerBrokenWithoutDial(t *testing.T) {
+	sessions := NewSessionManager(nil, nil, 1)
+	sessions.MarkBroken(nil)
+
+	_, _, err := sessions.Acquire(context.Background())
+	assert.ErrorContains(t, err, "reconnection is not supported")
+
+	// The slot of the failed attempt is given back.
+	assert.Equal(t, 0, len(sessions.slots))
+}
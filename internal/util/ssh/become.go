@@ -0,0 +1,56 @@
+package ssh
+
+import (
+	"io"
+	"strings"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// becomeMarker is printed by the elevated shell before the command runs, so that a failure
+// of sudo, doas or su can be told apart from a failure of the command itself.
+const becomeMarker = "__TERRAFORM_PROVIDER_LINUX_BECOME__"
+
+// wrapBecome returns command elevated with the configured method and the input to feed on stdin.
+func wrapBecome(become *util.Become, command string) (string, io.Reader) {
+	script := "echo " + becomeMarker + "; " + command
+
+	switch become.Method {
+	case "doas":
+		return Command("doas", "-n", "-u", become.User, "sh", "-c", script), nil
+	case "su":
+		return Command("su", "-s", "/bin/sh", become.User, "-c", script), nil
+	default:
+		if become.Password != "" {
+			return Command("sudo", "-S", "-p", "", "-u", become.User, "--", "sh", "-c", script), strings.NewReader(become.Password + "\n")
+		}
+		return Command("sudo", "-n", "-u", become.User, "--", "sh", "-c", script), nil
+	}
+}
+
+// unwrapBecome strips the marker from the output of an elevated command.
+// It returns false when the marker is missing, which means elevation itself failed.
+func unwrapBecome(stdout string) (string, bool) {
+	index := strings.Index(stdout, becomeMarker+"\n")
+	if index < 0 {
+		return stdout, false
+	}
+	return stdout[index+len(becomeMarker)+1:], true
+}
+
+func becomeError(become *util.Become, result *util.CommandResult, err error) *util.CommonError {
+	detail := strings.TrimSpace(result.Stderr)
+	if detail == "" && err != nil {
+		detail = err.Error()
+	}
+
+	diagnostic := diag.NewErrorDiagnostic(
+		"Privilege escalation failed",
+		"Failed to run command as \""+become.User+"\" with "+become.Method+": "+detail,
+	)
+	return &util.CommonError{
+		Error:       err,
+		Diagnostics: diag.Diagnostics{diagnostic},
+	}
+}
@@ -0,0 +1,45 @@
+package ssh
+
+import (
+	"io"
+	"terraform-provider-linux/internal/util"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestWrapBecomeSudo(t *testing.T) {
+	command, stdin := wrapBecome(&util.Become{Method: "sudo", User: "root"}, "echo 'a'")
+
+	assert.Equal(t, `sudo -n -u root -- sh -c 'echo `+becomeMarker+`; echo '\''a'\'''`, command)
+	assert.Assert(t, stdin == nil)
+}
+
+func TestWrapBecomeSudoPassword(t *testing.T) {
+	command, stdin := wrapBecome(&util.Become{Method: "sudo", User: "root", Password: "secret"}, "id")
+
+	assert.Equal(t, `sudo -S -p '' -u root -- sh -c 'echo `+becomeMarker+`; id'`, command)
+	input, err := io.ReadAll(stdin)
+	assert.NilError(t, err)
+	assert.Equal(t, "secret\n", string(input))
+}
+
+func TestWrapBecomeDoas(t *testing.T) {
+	command, _ := wrapBecome(&util.Become{Method: "doas", User: "admin"}, "id")
+
+	assert.Equal(t, `doas -n -u admin sh -c 'echo `+becomeMarker+`; id'`, command)
+}
+
+func TestUnwrapBecome(t *testing.T) {
+	out, elevated := unwrapBecome(becomeMarker + "\nuid=0(root)\n")
+
+	assert.Assert(t, elevated)
+	assert.Equal(t, "uid=0(root)\n", out)
+}
+
+func TestUnwrapBecomeFailed(t *testing.T) {
+	out, elevated := unwrapBecome("")
+
+	assert.Assert(t, !elevated)
+	assert.Equal(t, "", out)
+}
@@ -0,0 +1,41 @@
+package ssh
+
+import (
+	"strings"
+)
+
+// isSafe reports whether every byte of arg is read literally by a POSIX shell outside of quotes.
+// "=" is excluded so that a first word is never parsed as a variable assignment.
+func isSafe(arg string) bool {
+	for _, c := range []byte(arg) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("@%+:,./_-", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Quote returns arg in a form that a POSIX shell reads back as exactly one word equal to arg.
+// Arguments that need no quoting are returned unchanged to keep logged commands readable.
+func Quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if isSafe(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// Command builds a shell command line running argv, quoting every argument.
+// Commands must be built with it so that paths and names can never inject shell syntax.
+func Command(argv ...string) string {
+	quoted := make([]string, len(argv))
+	for index, arg := range argv {
+		quoted[index] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
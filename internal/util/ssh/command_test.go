@@ -0,0 +1,45 @@
+package ssh
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "/etc/passwd", Quote("/etc/passwd"))
+	assert.Equal(t, "''", Quote(""))
+	assert.Equal(t, "'/tmp/a b'", Quote("/tmp/a b"))
+	assert.Equal(t, `'it'\''s'`, Quote("it's"))
+	assert.Equal(t, "'a=b'", Quote("a=b"))
+	assert.Equal(t, "'$(id)'", Quote("$(id)"))
+}
+
+func TestCommand(t *testing.T) {
+	assert.Equal(t, "useradd 'bob; rm -rf /' --uid 1000", Command("useradd", "bob; rm -rf /", "--uid", "1000"))
+}
+
+// FuzzQuote checks that every quoted argument is read back unchanged by sh.
+func FuzzQuote(f *testing.F) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		f.Skip("sh is not available")
+	}
+
+	for _, seed := range []string{"", "plain", "a b", "it's", "''", `"\"`, "$HOME", "`id`", "$(id)", "a\nb", "*", "~", "-n", "a=b", "#", "\\", "!x", "\t"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, arg string) {
+		// Arguments of a process can not contain NUL bytes.
+		if strings.ContainsRune(arg, 0) {
+			t.Skip()
+		}
+
+		out, err := exec.Command(sh, "-c", Command("printf", "%s", arg)).Output()
+		assert.NilError(t, err)
+		assert.Equal(t, arg, string(out))
+	})
+}
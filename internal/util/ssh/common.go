@@ -1,49 +1,143 @@
 package ssh
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"strings"
 	"terraform-provider-linux/internal/util"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-func defaultErrorHandler(out []byte, err error) (util.Status, *util.CommonError) {
+// commandError describes why command failed, including the remote stderr when there is one.
+func commandError(command string, result *util.CommandResult, err error) *util.CommonError {
+	var detail string
+	switch {
+	case result.Signal != "":
+		detail = fmt.Sprintf("Command \"%s\" was killed by signal %s", command, result.Signal)
+	case result.ExitCode >= 0:
+		detail = fmt.Sprintf("Command \"%s\" exited with status %d", command, result.ExitCode)
+	default:
+		detail = fmt.Sprintf("Failed to run command \"%s\": %v", command, err)
+	}
+	if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+		detail = detail + ":\n" + stderr
+	}
+
+	return &util.CommonError{
+		Error: err,
+		Diagnostics: diag.Diagnostics{
+			diag.NewErrorDiagnostic("Command failed", detail),
+		},
+	}
+}
+
+func defaultErrorHandler(command string, result *util.CommandResult, err error) (util.Status, *util.CommonError) {
 	if err != nil {
-		return util.Failed, &util.CommonError{
-			Error: err,
-		}
+		// Command failures are deterministic, so they are reported without retrying.
+		return util.Success, commandError(command, result, err)
 	}
 	return util.Success, nil
 }
 
-func RunCommand(linuxCtx util.LinuxContext, command string, errorhandler func([]byte, error) (util.Status, *util.CommonError)) (util.Status, string, *util.CommonError) {
+// commandContext derives the deadline of a remote command from the Terraform context and the provider command_timeout.
+func commandContext(linuxCtx util.LinuxContext) (context.Context, context.CancelFunc) {
+	if linuxCtx.ProviderData.CommandTimeout > 0 {
+		return context.WithTimeout(linuxCtx.Ctx, linuxCtx.ProviderData.CommandTimeout)
+	}
+	return context.WithCancel(linuxCtx.Ctx)
+}
+
+// RunCommand runs command on the host and returns its result.
+// When the host can not be reached, the command is retried according to the provider retry policy.
+// Otherwise errorhandler may inspect the result and return util.Bottom to fall back to the default handling,
+// which reports any error with the remote stderr.
+func RunCommand(linuxCtx util.LinuxContext, command string, errorhandler func(*util.CommandResult, error) (util.Status, *util.CommonError)) (util.Status, *util.CommandResult, *util.CommonError) {
+	return runCommand(linuxCtx, command, nil, errorhandler)
+}
+
+// RunCommandWithStdin runs command like RunCommand, feeding input on its stdin.
+// Unlike the command, input is never logged, so secrets should be passed this way.
+func RunCommandWithStdin(linuxCtx util.LinuxContext, command string, input string, errorhandler func(*util.CommandResult, error) (util.Status, *util.CommonError)) (util.Status, *util.CommandResult, *util.CommonError) {
+	return runCommand(linuxCtx, command, &input, errorhandler)
+}
+
+func runCommand(linuxCtx util.LinuxContext, command string, input *string, errorhandler func(*util.CommandResult, error) (util.Status, *util.CommonError)) (util.Status, *util.CommandResult, *util.CommonError) {
 	tflog.Info(linuxCtx.Ctx, fmt.Sprintf("Running command \"%s\"", command))
-	var out []byte
-	errors := []*util.CommonError{}
+	var result *util.CommandResult
+	commonErrors := []*util.CommonError{}
+
+	ctx, cancel := commandContext(linuxCtx)
+	defer cancel()
+
+	executor := linuxCtx.ProviderData.Executor
+	become := linuxCtx.ProviderData.Become
 
 	fn := func() util.Status {
+		// Only the outcome of the last attempt is reported.
+		commonErrors = []*util.CommonError{}
+
+		// stdin is recreated for each attempt, since a failed attempt may have consumed it.
+		wrapped := command
+		var stdin io.Reader
+		if input != nil {
+			stdin = strings.NewReader(*input)
+		}
+		if become != nil {
+			var becomeStdin io.Reader
+			wrapped, becomeStdin = wrapBecome(become, command)
+			if becomeStdin != nil && stdin != nil {
+				// sudo reads the password up to the first newline and leaves the rest to the command.
+				stdin = io.MultiReader(becomeStdin, stdin)
+			} else if becomeStdin != nil {
+				stdin = becomeStdin
+			}
+		}
+
 		var err error
-		out, err = linuxCtx.ProviderData.SshClient.Run(command)
+		if stdin != nil {
+			result, err = executor.RunWithStdin(ctx, wrapped, stdin)
+		} else {
+			result, err = executor.Run(ctx, wrapped)
+		}
+		tflog.Debug(linuxCtx.Ctx, fmt.Sprintf("Command \"%s\" finished with status %d in %s", command, result.ExitCode, result.Duration))
+
+		if util.IsTransportError(err) && ctx.Err() == nil {
+			tflog.Warn(linuxCtx.Ctx, fmt.Sprintf("Failed to reach host while running command \"%s\": %v", command, err))
+			commonErrors = append(commonErrors, commandError(command, result, err))
+			return util.Failed
+		}
+
+		if become != nil {
+			var elevated bool
+			if result.Stdout, elevated = unwrapBecome(result.Stdout); !elevated && ctx.Err() == nil {
+				// Elevation failures are deterministic, so they are reported without retrying.
+				commonErrors = append(commonErrors, becomeError(become, result, err))
+				return util.Success
+			}
+		}
 
 		status := util.Bottom
 		var commonError *util.CommonError = nil
 
 		if errorhandler != nil {
-			status, commonError = errorhandler(out, err)
+			status, commonError = errorhandler(result, err)
 		}
 		if status == util.Bottom {
-			status, commonError = defaultErrorHandler(out, err)
+			status, commonError = defaultErrorHandler(command, result, err)
 		}
 
 		if commonError != nil {
-			errors = append(errors, commonError)
+			commonErrors = append(commonErrors, commonError)
 		}
 		return status
 	}
-	status := util.BackoffRetry(fn, 3)
-	if len(errors) != 0 {
-		return status, "", util.FoldCommonError(errors)
+	status := util.BackoffRetry(ctx, fn, linuxCtx.ProviderData.Retry)
+	if len(commonErrors) != 0 {
+		return status, result, util.FoldCommonError(commonErrors)
 	}
 
-	return status, string(out), nil
+	return status, result, nil
 }
@@ -0,0 +1,132 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"terraform-provider-linux/internal/util"
+	"terraform-provider-linux/internal/util/fake"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestCommandErrorExitStatus(t *testing.T) {
+	result := &util.CommandResult{
+		ExitCode: 6,
+		Stderr:   "useradd: group 'staff' does not exist\n",
+	}
+
+	commonError := commandError("useradd bob", result, errors.New("Process exited with status 6"))
+
+	assert.Equal(t, "Command failed", commonError.Diagnostics[0].Summary())
+	assert.Equal(t, "Command \"useradd bob\" exited with status 6:\nuseradd: group 'staff' does not exist", commonError.Diagnostics[0].Detail())
+}
+
+func TestCommandErrorSignal(t *testing.T) {
+	result := &util.CommandResult{
+		ExitCode: 137,
+		Signal:   "KILL",
+	}
+
+	commonError := commandError("sleep 60", result, errors.New("Process exited with status 137 from signal KILL"))
+
+	assert.Equal(t, "Command \"sleep 60\" was killed by signal KILL", commonError.Diagnostics[0].Detail())
+	assert.Assert(t, !result.Exited(137))
+}
+
+func TestCommandErrorTransport(t *testing.T) {
+	result := &util.CommandResult{
+		ExitCode: -1,
+	}
+
+	commonError := commandError("id", result, errors.New("EOF"))
+
+	assert.Equal(t, "Failed to run command \"id\": EOF", commonError.Diagnostics[0].Detail())
+}
+
+func newFakeContext(executor *fake.Executor) util.LinuxContext {
+	return util.NewLinuxContext(context.Background(), &util.LinuxProviderData{
+		Executor: executor,
+		Retry: util.RetryPolicy{
+			MaxRetries: 2,
+			BaseDelay:  time.Millisecond,
+		},
+	})
+}
+
+func TestRunCommandRetriesTransportErrors(t *testing.T) {
+	executor := fake.NewExecutor().On("id -u",
+		fake.Response{TransportError: io.EOF},
+		fake.Response{Stdout: "0\n"},
+	)
+
+	_, result, commonError := RunCommand(newFakeContext(executor), "id -u", nil)
+
+	assert.Assert(t, commonError == nil)
+	assert.Equal(t, "0\n", result.Stdout)
+	assert.Equal(t, 2, len(executor.Commands()))
+}
+
+func TestRunCommandDoesNotRetryCommandFailures(t *testing.T) {
+	executor := fake.NewExecutor().On("useradd bob", fake.Response{ExitCode: 9, Stderr: "useradd: user 'bob' already exists\n"})
+
+	_, _, commonError := RunCommand(newFakeContext(executor), "useradd bob", nil)
+
+	assert.Assert(t, commonError != nil)
+	assert.Equal(t, "Command \"useradd bob\" exited with status 9:\nuseradd: user 'bob' already exists", commonError.Diagnostics[0].Detail())
+	assert.Equal(t, 1, len(executor.Commands()))
+}
+
+func TestRunCommandBecome(t *testing.T) {
+	become := &util.Become{Method: "sudo", User: "root", Password: "secret"}
+	wrapped, _ := wrapBecome(become, "id -u")
+	executor := fake.NewExecutor().On(wrapped, fake.Response{Stdout: becomeMarker + "\n0\n"})
+	linuxCtx := newFakeContext(executor)
+	linuxCtx.ProviderData.Become = become
+
+	_, result, commonError := RunCommand(linuxCtx, "id -u", nil)
+
+	assert.Assert(t, commonError == nil)
+	assert.Equal(t, "0\n", result.Stdout)
+	assert.DeepEqual(t, []string{"secret\n"}, executor.Stdins())
+}
+
+func TestRunCommandBecomeFailed(t *testing.T) {
+	become := &util.Become{Method: "sudo", User: "root"}
+	wrapped, _ := wrapBecome(become, "id -u")
+	executor := fake.NewExecutor().On(wrapped, fake.Response{ExitCode: 1, Stderr: "sudo: a password is required\n"})
+	linuxCtx := newFakeContext(executor)
+	linuxCtx.ProviderData.Become = become
+
+	_, _, commonError := RunCommand(linuxCtx, "id -u", nil)
+
+	assert.Assert(t, commonError != nil)
+	assert.Equal(t, "Privilege escalation failed", commonError.Diagnostics[0].Summary())
+}
+
+func TestRunCommandWithStdinRetries(t *testing.T) {
+	executor := fake.NewExecutor().On("chpasswd -e",
+		fake.Response{TransportError: io.EOF},
+		fake.Response{},
+	)
+
+	_, _, commonError := RunCommandWithStdin(newFakeContext(executor), "chpasswd -e", "bob:$6$hash\n", nil)
+
+	assert.Assert(t, commonError == nil)
+	assert.DeepEqual(t, []string{"bob:$6$hash\n", "bob:$6$hash\n"}, executor.Stdins())
+}
+
+func TestRunCommandWithStdinBecome(t *testing.T) {
+	become := &util.Become{Method: "sudo", User: "root", Password: "secret"}
+	wrapped, _ := wrapBecome(become, "chpasswd -e")
+	executor := fake.NewExecutor().On(wrapped, fake.Response{Stdout: becomeMarker + "\n"})
+	linuxCtx := newFakeContext(executor)
+	linuxCtx.ProviderData.Become = become
+
+	_, _, commonError := RunCommandWithStdin(linuxCtx, "chpasswd -e", "bob:$6$hash\n", nil)
+
+	assert.Assert(t, commonError == nil)
+	assert.DeepEqual(t, []string{"secret\nbob:$6$hash\n"}, executor.Stdins())
+}
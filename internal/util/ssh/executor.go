@@ -0,0 +1,213 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"terraform-provider-linux/internal/util"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/melbahja/goph"
+	"github.com/pkg/sftp"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Executor runs commands and transfers files over the sessions of a session manager.
+type Executor struct {
+	sessions *util.SessionManager
+}
+
+var _ util.Executor = &Executor{}
+
+func NewExecutor(sessions *util.SessionManager) *Executor {
+	return &Executor{
+		sessions: sessions,
+	}
+}
+
+// acquire returns a connection with a free session slot, reporting a failed redial as a transport error.
+func (e *Executor) acquire(ctx context.Context) (*goph.Client, func(), error) {
+	client, release, err := e.sessions.Acquire(ctx)
+	if err != nil && ctx.Err() == nil {
+		return nil, nil, &util.TransportError{Err: err}
+	}
+	return client, release, err
+}
+
+// transportError marks client as broken when err means the connection was lost.
+func (e *Executor) transportError(ctx context.Context, client *goph.Client, err error) error {
+	if isTransportError(err) && ctx.Err() == nil {
+		e.sessions.MarkBroken(client)
+		return &util.TransportError{Err: err}
+	}
+	return err
+}
+
+func (e *Executor) Run(ctx context.Context, command string) (*util.CommandResult, error) {
+	return e.RunWithStdin(ctx, command, nil)
+}
+
+func (e *Executor) RunWithStdin(ctx context.Context, command string, stdin io.Reader) (*util.CommandResult, error) {
+	client, release, err := e.acquire(ctx)
+	if err != nil {
+		return &util.CommandResult{ExitCode: -1}, err
+	}
+	defer release()
+
+	result, err := run(ctx, client, command, stdin)
+	return result, e.transportError(ctx, client, err)
+}
+
+func (e *Executor) Upload(ctx context.Context, content io.Reader, remotePath string) error {
+	return e.withSftp(ctx, func(sftpClient *sftp.Client) error {
+		remote, err := sftpClient.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+		if err != nil {
+			return err
+		}
+
+		// SFTP creates files with the umask of the server, so permissions are restricted before writing.
+		err = remote.Chmod(0600)
+		if err == nil {
+			_, err = io.Copy(remote, content)
+		}
+		if closeErr := remote.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+}
+
+func (e *Executor) Download(ctx context.Context, remotePath string, content io.Writer) error {
+	return e.withSftp(ctx, func(sftpClient *sftp.Client) error {
+		remote, err := sftpClient.Open(remotePath)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+
+		_, err = io.Copy(content, remote)
+		return err
+	})
+}
+
+func (e *Executor) Stat(ctx context.Context, remotePath string) (*util.FileStat, error) {
+	var stat *util.FileStat
+	err := e.withSftp(ctx, func(sftpClient *sftp.Client) error {
+		fileInfo, err := sftpClient.Stat(remotePath)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		stat = &util.FileStat{
+			Mode: fileInfo.Mode(),
+			Size: fileInfo.Size(),
+		}
+		if fileStat, ok := fileInfo.Sys().(*sftp.FileStat); ok {
+			stat.Uid = int64(fileStat.UID)
+			stat.Gid = int64(fileStat.GID)
+		}
+		return nil
+	})
+	return stat, err
+}
+
+func (e *Executor) User() string {
+	return e.sessions.User()
+}
+
+// Close closes the connection to the host.
+func (e *Executor) Close() error {
+	return e.sessions.Close()
+}
+
+// withSftp runs fn on a new SFTP session, which holds a slot of the session manager until fn returns.
+func (e *Executor) withSftp(ctx context.Context, fn func(sftpClient *sftp.Client) error) error {
+	client, release, err := e.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	sftpClient, err := client.NewSftp()
+	if err != nil {
+		return e.transportError(ctx, client, err)
+	}
+	defer sftpClient.Close()
+	stop := closeOnDone(ctx, sftpClient)
+	defer stop()
+
+	return e.transportError(ctx, client, fn(sftpClient))
+}
+
+// closeOnDone closes closer when ctx is cancelled, which aborts a running transfer.
+func closeOnDone(ctx context.Context, closer io.Closer) func() {
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			closer.Close()
+		case <-stopped:
+		}
+	}()
+	return func() {
+		close(stopped)
+	}
+}
+
+// run executes command in a new session. The remote process is interrupted when ctx is done.
+// The returned result is never nil, and its ExitCode is -1 unless the server reported an exit status.
+func run(ctx context.Context, client *goph.Client, command string, stdin io.Reader) (*util.CommandResult, error) {
+	start := time.Now()
+	result := &util.CommandResult{
+		ExitCode: -1,
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return result, err
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Start(command); err != nil {
+		return result, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+		result.Duration = time.Since(start)
+
+		var exitError *gossh.ExitError
+		switch {
+		case err == nil:
+			result.ExitCode = 0
+		case errors.As(err, &exitError):
+			result.ExitCode = exitError.ExitStatus()
+			result.Signal = exitError.Signal()
+		}
+		return result, err
+	case <-ctx.Done():
+		if err := session.Signal(gossh.SIGINT); err != nil {
+			tflog.Debug(ctx, fmt.Sprintf("Failed to interrupt command: %v", err))
+		}
+		result.Duration = time.Since(start)
+		return result, ctx.Err()
+	}
+}
@@ -0,0 +1,60 @@
+package ssh_test
+
+import (
+	"context"
+	"sync/atomic"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"terraform-provider-linux/internal/util/sshtest"
+	"testing"
+	"time"
+
+	"github.com/melbahja/goph"
+	"gotest.tools/assert"
+)
+
+func TestRunCommandRedialsOnceAfterDisconnect(t *testing.T) {
+	var disconnected atomic.Bool
+	server := sshtest.NewServer(t)
+	server.
+		HandleFunc(func(command string, stdin string) (sshtest.Response, bool) {
+			if command != "id -u" {
+				return sshtest.Response{}, false
+			}
+			// The first attempt loses the connection before the exit status is sent.
+			if !disconnected.Swap(true) {
+				server.Disconnect()
+			}
+			return sshtest.Response{Stdout: "0\n"}, true
+		}).
+		Handle("useradd bob", sshtest.Response{ExitCode: 9, Stderr: "useradd: user 'bob' already exists\n"})
+
+	dials := 0
+	dial := func() (*goph.Client, error) {
+		dials++
+		return server.Client(t, "root"), nil
+	}
+	sessions := util.NewSessionManager(server.Client(t, "root"), dial, util.DefaultMaxSessions)
+	linuxCtx := util.NewLinuxContext(context.Background(), &util.LinuxProviderData{
+		Executor: sshUtil.NewExecutor(sessions),
+		Retry: util.RetryPolicy{
+			MaxRetries: 2,
+			BaseDelay:  time.Millisecond,
+		},
+	})
+
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, "id -u", nil)
+
+	assert.Assert(t, commonError == nil, "%v", commonError)
+	assert.Equal(t, "0\n", result.Stdout)
+	assert.Equal(t, 1, dials)
+	assert.DeepEqual(t, []string{"id -u", "id -u"}, server.Commands())
+
+	// A command that ran and failed is neither retried nor a reason to redial.
+	_, result, commonError = sshUtil.RunCommand(linuxCtx, "useradd bob", nil)
+
+	assert.Assert(t, commonError != nil)
+	assert.Assert(t, result.Exited(9))
+	assert.Equal(t, 1, dials)
+	assert.DeepEqual(t, []string{"id -u", "id -u", "useradd bob"}, server.Commands())
+}
@@ -0,0 +1,27 @@
+package ssh
+
+import (
+	"time"
+
+	"github.com/melbahja/goph"
+)
+
+// StartKeepalive sends an OpenSSH keepalive request every interval so that idle connections
+// are not dropped by firewalls. Once a request fails, broken is called and keepalive stops.
+func StartKeepalive(client *goph.Client, interval time.Duration, broken func()) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				broken()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,42 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// isTransportError reports whether err means the connection to the host broke, as opposed to the
+// command failing. Only transport errors are worth retrying: a command that ran and failed
+// would fail the same way again, and running a non-idempotent command twice may apply it twice.
+func isTransportError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var exitError *gossh.ExitError
+	if errors.As(err, &exitError) {
+		return false
+	}
+	// The channel was closed before the command reported an exit status.
+	var exitMissingError *gossh.ExitMissingError
+	if errors.As(err, &exitMissingError) {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return true
+	}
+
+	return strings.Contains(err.Error(), "handshake failed")
+}
@@ -0,0 +1,28 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	gossh "golang.org/x/crypto/ssh"
+	"gotest.tools/assert"
+)
+
+func TestIsTransportError(t *testing.T) {
+	assert.Assert(t, isTransportError(io.EOF))
+	assert.Assert(t, isTransportError(fmt.Errorf("failed to open session: %w", io.EOF)))
+	assert.Assert(t, isTransportError(&gossh.ExitMissingError{}))
+	assert.Assert(t, isTransportError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}))
+	assert.Assert(t, isTransportError(errors.New("ssh: handshake failed: read tcp: i/o timeout")))
+}
+
+func TestIsTransportErrorCommandFailure(t *testing.T) {
+	assert.Assert(t, !isTransportError(nil))
+	assert.Assert(t, !isTransportError(&gossh.ExitError{}))
+	assert.Assert(t, !isTransportError(context.Canceled))
+	assert.Assert(t, !isTransportError(context.DeadlineExceeded))
+}
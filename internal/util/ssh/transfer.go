@@ -0,0 +1,246 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"terraform-provider-linux/internal/util"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+func transferError(summary string, err error) *util.CommonError {
+	return &util.CommonError{
+		Error: err,
+		Diagnostics: diag.Diagnostics{
+			diag.NewErrorDiagnostic(summary, err.Error()),
+		},
+	}
+}
+
+func temporaryPath(remotePath string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return path.Join(path.Dir(remotePath), "."+path.Base(remotePath)+".tmp-"+hex.EncodeToString(suffix)), nil
+}
+
+// RemoteChecksum returns the SHA-256 checksum of remotePath computed on the server.
+// The file is hashed from stdin, since sha256sum escapes its output when a file name contains a backslash or newline.
+func RemoteChecksum(linuxCtx util.LinuxContext, remotePath string) (string, *util.CommonError) {
+	_, result, commonError := RunCommand(linuxCtx, Command("sha256sum")+" < "+Command(remotePath), nil)
+	if commonError != nil {
+		return "", commonError
+	}
+
+	splitted := strings.Fields(result.Stdout)
+	if len(splitted) < 1 {
+		return "", transferError("Failed to get checksum", fmt.Errorf("empty sha256sum output for %s", remotePath))
+	}
+
+	return splitted[0], nil
+}
+
+// loginContext returns linuxCtx without become, for commands that must run as the login user.
+func loginContext(linuxCtx util.LinuxContext) util.LinuxContext {
+	providerData := *linuxCtx.ProviderData
+	providerData.Become = nil
+	return util.NewLinuxContext(linuxCtx.Ctx, &providerData)
+}
+
+// stagingDirectory creates a private directory, mode 0700 and owned by the login user, to exchange files through SFTP
+// when commands are elevated, because SFTP itself runs without privileges. It must be removed with removeStaging.
+func stagingDirectory(linuxCtx util.LinuxContext) (string, *util.CommonError) {
+	_, result, commonError := RunCommand(loginContext(linuxCtx), Command("mktemp", "-d"), nil)
+	if commonError != nil {
+		return "", commonError
+	}
+
+	directory := strings.TrimSpace(result.Stdout)
+	if !path.IsAbs(directory) {
+		return "", transferError("Failed to create staging directory", fmt.Errorf("unexpected mktemp output %q", result.Stdout))
+	}
+	return directory, nil
+}
+
+// removeStaging deletes a directory created by stagingDirectory with its content.
+func removeStaging(linuxCtx util.LinuxContext, directory string) {
+	_, _, commonError := RunCommand(loginContext(linuxCtx), Command("rm", "-rf", "--", directory), nil)
+	if commonError != nil {
+		tflog.Warn(linuxCtx.Ctx, fmt.Sprintf("Failed to remove staging directory \"%s\": %v", directory, commonError.Error))
+	}
+}
+
+// removeRemote deletes remotePath, logging instead of failing since it only cleans up temporary files.
+func removeRemote(linuxCtx util.LinuxContext, remotePath string) {
+	_, _, commonError := RunCommand(linuxCtx, Command("rm", "-f", "--", remotePath), nil)
+	if commonError != nil {
+		tflog.Warn(linuxCtx.Ctx, fmt.Sprintf("Failed to remove temporary file \"%s\": %v", remotePath, commonError.Error))
+	}
+}
+
+// byteCounter counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// UploadOptions are applied to the temporary file before it is renamed over the target, so that the target
+// never exists with other permissions. Empty fields are left unchanged: mode 0600, owned by the uploading user.
+type UploadOptions struct {
+	Mode  string
+	Owner string
+	Group string
+}
+
+// SetPermissions changes ownership and then mode of remotePath, since chown clears setuid and setgid bits.
+// Empty owner, group or mode are left unchanged. With recursive, ownership is also changed below a directory,
+// while the mode only applies to remotePath.
+func SetPermissions(linuxCtx util.LinuxContext, remotePath string, mode string, owner string, group string, recursive bool) *util.CommonError {
+	if owner != "" || group != "" {
+		ownership := owner
+		if group != "" {
+			ownership = ownership + ":" + group
+		}
+		argv := []string{"chown"}
+		if recursive {
+			argv = append(argv, "-R")
+		}
+		argv = append(argv, "--", ownership, remotePath)
+		_, _, commonError := RunCommand(linuxCtx, Command(argv...), nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+	if mode != "" {
+		_, _, commonError := RunCommand(linuxCtx, Command("chmod", mode, "--", remotePath), nil)
+		if commonError != nil {
+			return commonError
+		}
+	}
+	return nil
+}
+
+// Upload streams content to remotePath with default options. See UploadWithOptions.
+func Upload(linuxCtx util.LinuxContext, content io.Reader, remotePath string) (string, *util.CommonError) {
+	return UploadWithOptions(linuxCtx, content, remotePath, UploadOptions{})
+}
+
+// UploadWithOptions streams content to remotePath and returns the SHA-256 checksum of the uploaded data.
+// Content is written to a temporary file next to remotePath, created with mode 0600, verified against the checksum
+// computed while streaming, given the mode and ownership of options, and then renamed over remotePath so readers
+// never observe a partial file.
+// When commands are elevated, the data is staged in a private directory of the login user first
+// and copied next to remotePath as the become user. The staging directory is removed on every path.
+func UploadWithOptions(linuxCtx util.LinuxContext, content io.Reader, remotePath string, options UploadOptions) (string, *util.CommonError) {
+	tflog.Info(linuxCtx.Ctx, fmt.Sprintf("Uploading to \"%s\"", remotePath))
+
+	tmpPath, err := temporaryPath(remotePath)
+	if err != nil {
+		return "", transferError("Failed to create temporary path", err)
+	}
+	uploadPath := tmpPath
+	if linuxCtx.ProviderData.Become != nil {
+		staging, commonError := stagingDirectory(linuxCtx)
+		if commonError != nil {
+			return "", commonError
+		}
+		defer removeStaging(linuxCtx, staging)
+		uploadPath = path.Join(staging, path.Base(remotePath))
+	}
+	cleanup := func() {
+		removeRemote(linuxCtx, tmpPath)
+	}
+
+	hash := sha256.New()
+	var written byteCounter
+	err = linuxCtx.ProviderData.Executor.Upload(linuxCtx.Ctx, io.TeeReader(content, io.MultiWriter(hash, &written)), uploadPath)
+	if err != nil {
+		cleanup()
+		return "", transferError("Failed to upload file", err)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	if uploadPath != tmpPath {
+		_, _, commonError := RunCommand(linuxCtx, Command("cp", "--", uploadPath, tmpPath), nil)
+		if commonError != nil {
+			cleanup()
+			return "", commonError
+		}
+	}
+
+	remoteChecksum, commonError := RemoteChecksum(linuxCtx, tmpPath)
+	if commonError != nil {
+		cleanup()
+		return "", commonError
+	}
+	if remoteChecksum != checksum {
+		cleanup()
+		return "", transferError("Checksum mismatch", fmt.Errorf("uploaded %d bytes with checksum %s, but server reported %s", written, checksum, remoteChecksum))
+	}
+
+	commonError = SetPermissions(linuxCtx, tmpPath, options.Mode, options.Owner, options.Group, false)
+	if commonError != nil {
+		cleanup()
+		return "", commonError
+	}
+
+	_, _, commonError = RunCommand(linuxCtx, Command("mv", "-f", "--", tmpPath, remotePath), nil)
+	if commonError != nil {
+		cleanup()
+		return "", commonError
+	}
+
+	tflog.Debug(linuxCtx.Ctx, fmt.Sprintf("Uploaded %d bytes to \"%s\" with checksum %s", written, remotePath, checksum))
+	return checksum, nil
+}
+
+// Download streams remotePath into content and returns the SHA-256 checksum of the downloaded data.
+// When commands are elevated, remotePath is first copied by the become user into a file of mode 0600
+// that the login user created in its private staging directory, which is removed on every path.
+func Download(linuxCtx util.LinuxContext, remotePath string, content io.Writer) (string, *util.CommonError) {
+	tflog.Info(linuxCtx.Ctx, fmt.Sprintf("Downloading from \"%s\"", remotePath))
+	executor := linuxCtx.ProviderData.Executor
+
+	downloadPath := remotePath
+	if linuxCtx.ProviderData.Become != nil {
+		staging, commonError := stagingDirectory(linuxCtx)
+		if commonError != nil {
+			return "", commonError
+		}
+		defer removeStaging(linuxCtx, staging)
+		downloadPath = path.Join(staging, path.Base(remotePath))
+
+		// The file is created empty by the login user, so writing it keeps its owner and mode.
+		if err := executor.Upload(linuxCtx.Ctx, strings.NewReader(""), downloadPath); err != nil {
+			return "", transferError("Failed to create staging file", err)
+		}
+		_, _, commonError = RunCommand(linuxCtx, Command("cat", "--", remotePath)+" > "+Command(downloadPath), nil)
+		if commonError != nil {
+			return "", commonError
+		}
+	} else {
+		stat, err := executor.Stat(linuxCtx.Ctx, remotePath)
+		if err != nil {
+			return "", transferError("Failed to stat remote file", err)
+		}
+		if stat == nil {
+			return "", transferError("Path not found", fmt.Errorf("%s does not exist", remotePath))
+		}
+	}
+
+	hash := sha256.New()
+	if err := executor.Download(linuxCtx.Ctx, downloadPath, io.MultiWriter(content, hash)); err != nil {
+		return "", transferError("Failed to download file", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,152 @@
+package ssh_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-linux/internal/util"
+	"terraform-provider-linux/internal/util/local"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"terraform-provider-linux/internal/util/sshtest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const stagingDirectory = "/tmp/tmp.staging"
+
+// becomeServer answers mktemp and rm of the staging directory as the login user,
+// and passes the script of commands elevated with sudo to elevated.
+func becomeServer(t *testing.T, elevated func(server *sshtest.Server, script string) sshtest.Response) (*sshtest.Server, util.LinuxContext) {
+	server := sshtest.NewServer(t)
+	server.HandleFunc(func(command string, stdin string) (sshtest.Response, bool) {
+		switch command {
+		case "mktemp -d":
+			assert.NilError(t, os.Mkdir(server.Path(stagingDirectory), 0700))
+			return sshtest.Response{Stdout: stagingDirectory + "\n"}, true
+		case "rm -rf -- " + stagingDirectory:
+			assert.NilError(t, os.RemoveAll(server.Path(stagingDirectory)))
+			return sshtest.Response{}, true
+		}
+
+		const prefix = "sudo -n -u root -- sh -c "
+		if !strings.HasPrefix(command, prefix) {
+			return sshtest.Response{}, false
+		}
+		script := strings.Trim(strings.TrimPrefix(command, prefix), "'")
+		_, script, _ = strings.Cut(script, "; ")
+		response := elevated(server, script)
+		response.Stdout = "__TERRAFORM_PROVIDER_LINUX_BECOME__\n" + response.Stdout
+		return response, true
+	})
+	assert.NilError(t, os.MkdirAll(server.Path("/tmp"), 0755))
+
+	linuxCtx := server.LinuxContext(t)
+	providerData := *linuxCtx.ProviderData
+	providerData.Become = &util.Become{Method: "sudo", User: "root"}
+	return server, util.NewLinuxContext(linuxCtx.Ctx, &providerData)
+}
+
+func assertStagingRemoved(t *testing.T, server *sshtest.Server) {
+	t.Helper()
+
+	commands := server.Commands()
+	assert.Equal(t, "mktemp -d", commands[0])
+	assert.Equal(t, "rm -rf -- "+stagingDirectory, commands[len(commands)-1])
+	_, err := os.Stat(server.Path(stagingDirectory))
+	assert.Assert(t, os.IsNotExist(err), "%v", err)
+}
+
+func TestUploadBecomeFailure(t *testing.T) {
+	staged := false
+	server, linuxCtx := becomeServer(t, func(server *sshtest.Server, script string) sshtest.Response {
+		if strings.HasPrefix(script, "cp -- "+stagingDirectory+"/motd ") {
+			directory, err := os.Stat(server.Path(stagingDirectory))
+			assert.NilError(t, err)
+			assert.Equal(t, os.FileMode(0700), directory.Mode().Perm())
+			file, err := os.Stat(server.Path(stagingDirectory + "/motd"))
+			assert.NilError(t, err)
+			assert.Equal(t, os.FileMode(0600), file.Mode().Perm())
+			staged = true
+			return sshtest.Response{ExitCode: 1, Stderr: "cp: No space left on device\n"}
+		}
+		if strings.HasPrefix(script, "rm -f -- /etc/.motd.tmp-") {
+			return sshtest.Response{}
+		}
+		return sshtest.Response{ExitCode: 127}
+	})
+
+	_, commonError := sshUtil.Upload(linuxCtx, strings.NewReader("hello\n"), "/etc/motd")
+
+	assert.Assert(t, commonError != nil)
+	assert.Assert(t, staged)
+	assertStagingRemoved(t, server)
+}
+
+func TestDownloadBecome(t *testing.T) {
+	server, linuxCtx := becomeServer(t, func(server *sshtest.Server, script string) sshtest.Response {
+		if script != "cat -- /etc/shadow > "+stagingDirectory+"/shadow" {
+			return sshtest.Response{ExitCode: 127}
+		}
+		// The login user created the file beforehand, so the become user only fills it.
+		stagedPath := server.Path(stagingDirectory + "/shadow")
+		file, err := os.Stat(stagedPath)
+		assert.NilError(t, err)
+		assert.Equal(t, os.FileMode(0600), file.Mode().Perm())
+		assert.NilError(t, os.WriteFile(stagedPath, []byte("root:*:19000::::::\n"), 0644))
+		return sshtest.Response{}
+	})
+
+	var content bytes.Buffer
+	_, commonError := sshUtil.Download(linuxCtx, "/etc/shadow", &content)
+
+	assert.Assert(t, commonError == nil, "%v", commonError)
+	assert.Equal(t, "root:*:19000::::::\n", content.String())
+	assertStagingRemoved(t, server)
+}
+
+func TestDownloadBecomeFailure(t *testing.T) {
+	server, linuxCtx := becomeServer(t, func(server *sshtest.Server, script string) sshtest.Response {
+		return sshtest.Response{ExitCode: 1, Stderr: "cat: /etc/shadow: No such file or directory\n"}
+	})
+
+	var content bytes.Buffer
+	_, commonError := sshUtil.Download(linuxCtx, "/etc/shadow", &content)
+
+	assert.Assert(t, commonError != nil)
+	assertStagingRemoved(t, server)
+}
+
+func TestUploadEscapedName(t *testing.T) {
+	linuxCtx := util.NewLinuxContext(context.Background(), &util.LinuxProviderData{Executor: local.NewExecutor()})
+	// sha256sum prefixes its output with a backslash for such names, which must not break the verification.
+	directory := filepath.Join(t.TempDir(), "back\\slash\nline")
+	assert.NilError(t, os.Mkdir(directory, 0755))
+	remotePath := filepath.Join(directory, "motd")
+
+	checksum, commonError := sshUtil.Upload(linuxCtx, strings.NewReader("hello\n"), remotePath)
+
+	assert.Assert(t, commonError == nil, "%v", commonError)
+	assert.Equal(t, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", checksum)
+	content, err := os.ReadFile(remotePath)
+	assert.NilError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+
+	checksum, commonError = sshUtil.RemoteChecksum(linuxCtx, remotePath)
+	assert.Assert(t, commonError == nil, "%v", commonError)
+	assert.Equal(t, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", checksum)
+}
+
+func TestSetPermissions(t *testing.T) {
+	server := sshtest.NewServer(t).
+		Handle("chown -R -- daemon:adm /srv/app", sshtest.Response{}).
+		Handle("chmod 2750 -- /srv/app", sshtest.Response{})
+
+	commonError := sshUtil.SetPermissions(server.LinuxContext(t), "/srv/app", "2750", "daemon", "adm", true)
+
+	assert.Assert(t, commonError == nil, "%v", commonError)
+	// The mode goes last, so the setgid bit is not cleared by chown.
+	assert.DeepEqual(t, []string{"chown -R -- daemon:adm /srv/app", "chmod 2750 -- /srv/app"}, server.Commands())
+}
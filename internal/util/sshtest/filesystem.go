@@ -0,0 +1,112 @@
+package sshtest
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+)
+
+// rootedFilesystem serves SFTP from a directory, which appears as "/" to clients.
+type rootedFilesystem struct {
+	root string
+}
+
+func (f *rootedFilesystem) handlers() sftp.Handlers {
+	return sftp.Handlers{
+		FileGet:  f,
+		FilePut:  f,
+		FileCmd:  f,
+		FileList: f,
+	}
+}
+
+// path maps remotePath to the local filesystem. Paths can not escape the root.
+func (f *rootedFilesystem) path(remotePath string) string {
+	return filepath.Join(f.root, filepath.FromSlash(path.Clean("/"+remotePath)))
+}
+
+func (f *rootedFilesystem) Fileread(request *sftp.Request) (io.ReaderAt, error) {
+	return os.Open(f.path(request.Filepath))
+}
+
+func (f *rootedFilesystem) Filewrite(request *sftp.Request) (io.WriterAt, error) {
+	flags := os.O_WRONLY
+	pflags := request.Pflags()
+	if pflags.Creat {
+		flags |= os.O_CREATE
+	}
+	if pflags.Trunc {
+		flags |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flags |= os.O_EXCL
+	}
+	return os.OpenFile(f.path(request.Filepath), flags, 0644)
+}
+
+func (f *rootedFilesystem) Filecmd(request *sftp.Request) error {
+	switch request.Method {
+	case "Setstat":
+		if request.AttrFlags().Permissions {
+			return os.Chmod(f.path(request.Filepath), request.Attributes().FileMode())
+		}
+		return nil
+	case "Rename", "PosixRename":
+		return os.Rename(f.path(request.Filepath), f.path(request.Target))
+	case "Rmdir", "Remove":
+		return os.Remove(f.path(request.Filepath))
+	case "Mkdir":
+		return os.Mkdir(f.path(request.Filepath), 0755)
+	case "Symlink":
+		return os.Symlink(request.Target, f.path(request.Filepath))
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(fileInfos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(fileInfos, l[offset:])
+	if n < len(fileInfos) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *rootedFilesystem) Filelist(request *sftp.Request) (sftp.ListerAt, error) {
+	switch request.Method {
+	case "List":
+		entries, err := os.ReadDir(f.path(request.Filepath))
+		if err != nil {
+			return nil, err
+		}
+		fileInfos := listerAt{}
+		for _, entry := range entries {
+			fileInfo, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			fileInfos = append(fileInfos, fileInfo)
+		}
+		return fileInfos, nil
+	case "Stat":
+		fileInfo, err := os.Stat(f.path(request.Filepath))
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{fileInfo}, nil
+	case "Lstat":
+		fileInfo, err := os.Lstat(f.path(request.Filepath))
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{fileInfo}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
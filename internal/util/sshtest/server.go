@@ -0,0 +1,383 @@
+// Package sshtest provides an in-process SSH server for unit tests. Commands are answered from a script
+// instead of being executed, and SFTP is served from a temporary directory standing in for "/".
+// Acceptance tests run against a real host instead, see the README.
+package sshtest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"terraform-provider-linux/internal/util"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"testing"
+
+	"github.com/melbahja/goph"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Password is accepted for every user.
+const Password = "sshtest"
+
+// Response is the scripted outcome of a command.
+type Response struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// HandlerFunc answers command, which received stdin. It returns false to leave command to the next handler.
+type HandlerFunc func(command string, stdin string) (Response, bool)
+
+type Server struct {
+	HostKey ssh.PublicKey
+
+	listener net.Listener
+	config   *ssh.ServerConfig
+	files    *rootedFilesystem
+
+	mu        sync.Mutex
+	responses map[string]Response
+	handlers  []HandlerFunc
+	commands  []string
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer starts a server listening on localhost, which is stopped when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatalf("Failed to create host key signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != Password {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	server := &Server{
+		HostKey:   signer.PublicKey(),
+		listener:  listener,
+		config:    config,
+		files:     &rootedFilesystem{root: t.TempDir()},
+		responses: map[string]Response{},
+		conns:     map[net.Conn]struct{}{},
+	}
+
+	server.wg.Add(1)
+	go server.serve()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Addr returns the host and port the server listens on.
+func (s *Server) Addr() (string, uint) {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	parsedPort, _ := strconv.ParseUint(port, 10, 16)
+	return host, uint(parsedPort)
+}
+
+// Path returns where remotePath is stored on the local filesystem.
+func (s *Server) Path(remotePath string) string {
+	return s.files.path(remotePath)
+}
+
+// Handle scripts the response to command, which must match exactly.
+func (s *Server) Handle(command string, response Response) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[command] = response
+	return s
+}
+
+// HandleFunc adds handler, consulted in order for commands without an exact match.
+// Commands nobody answers exit with status 127.
+func (s *Server) HandleFunc(handler HandlerFunc) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers = append(s.handlers, handler)
+	return s
+}
+
+// Commands returns every command received so far, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.commands...)
+}
+
+// Client connects to the server as user, trusting only its host key. The connection is closed when the test ends.
+func (s *Server) Client(t testing.TB, user string) *goph.Client {
+	t.Helper()
+
+	host, port := s.Addr()
+	client, err := goph.NewConn(&goph.Config{
+		User:     user,
+		Addr:     host,
+		Port:     port,
+		Auth:     goph.Password(Password),
+		Timeout:  goph.DefaultTimeout,
+		Callback: ssh.FixedHostKey(s.HostKey),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test server: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return client
+}
+
+// LinuxContext returns a context whose commands run on the server as root.
+func (s *Server) LinuxContext(t testing.TB) util.LinuxContext {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sessions := util.NewSessionManager(s.Client(t, "root"), nil, util.DefaultMaxSessions)
+	return util.LinuxContext{
+		Ctx: ctx,
+		ProviderData: &util.LinuxProviderData{
+			Executor: sshUtil.NewExecutor(sessions),
+		},
+	}
+}
+
+// Connections returns the number of client connections currently open.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// Disconnect drops every open connection, as a network failure would, while still accepting new ones.
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Close stops the server, closing connections clients left open, and waits for them to finish.
+func (s *Server) Close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for newChannel := range channels {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.serveSession(channel, requests)
+			}()
+		case "direct-tcpip":
+			wg.Add(1)
+			go func(newChannel ssh.NewChannel) {
+				defer wg.Done()
+				s.forward(newChannel)
+			}(newChannel)
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "only session and direct-tcpip channels are supported")
+		}
+	}
+}
+
+// forward connects a direct-tcpip channel to its destination, so that the server can be used as a bastion.
+func (s *Server) forward(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, "invalid direct-tcpip payload")
+		return
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.FormatUint(uint64(payload.Port), 10)))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+	go ssh.DiscardRequests(requests)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(conn, channel)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(channel, conn)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func (s *Server) serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for request := range requests {
+		switch request.Type {
+		case "exec":
+			var payload struct {
+				Command string
+			}
+			if err := ssh.Unmarshal(request.Payload, &payload); err != nil {
+				request.Reply(false, nil)
+				continue
+			}
+			request.Reply(true, nil)
+			s.exec(channel, payload.Command)
+			return
+		case "subsystem":
+			var payload struct {
+				Name string
+			}
+			if err := ssh.Unmarshal(request.Payload, &payload); err != nil || payload.Name != "sftp" {
+				request.Reply(false, nil)
+				continue
+			}
+			request.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			server := sftp.NewRequestServer(channel, s.files.handlers())
+			server.Serve()
+			server.Close()
+			return
+		default:
+			if request.WantReply {
+				request.Reply(false, nil)
+			}
+		}
+	}
+}
+
+func (s *Server) exec(channel ssh.Channel, command string) {
+	stdin, _ := io.ReadAll(channel)
+	response := s.respond(command, string(stdin))
+
+	io.WriteString(channel, response.Stdout)
+	io.WriteString(channel.Stderr(), response.Stderr)
+	channel.SendRequest("exit-status", false, ssh.Marshal(struct {
+		Status uint32
+	}{
+		Status: uint32(response.ExitCode),
+	}))
+}
+
+func (s *Server) respond(command string, stdin string) Response {
+	s.mu.Lock()
+	s.commands = append(s.commands, command)
+	response, ok := s.responses[command]
+	handlers := append([]HandlerFunc{}, s.handlers...)
+	s.mu.Unlock()
+
+	if ok {
+		return response
+	}
+	for _, handler := range handlers {
+		if response, ok := handler(command, stdin); ok {
+			return response
+		}
+	}
+	return Response{
+		ExitCode: 127,
+		Stderr:   fmt.Sprintf("sh: %s: not scripted\n", command),
+	}
+}
@@ -0,0 +1,71 @@
+package sshtest
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	sshUtil "terraform-provider-linux/internal/util/ssh"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRunCommand(t *testing.T) {
+	server := NewServer(t).
+		Handle("id -u", Response{Stdout: "0\n"}).
+		Handle("false", Response{ExitCode: 1, Stderr: "failed\n"})
+	linuxCtx := server.LinuxContext(t)
+
+	_, result, commonError := sshUtil.RunCommand(linuxCtx, "id -u", nil)
+	assert.Assert(t, commonError == nil)
+	assert.Equal(t, "0\n", result.Stdout)
+
+	_, result, commonError = sshUtil.RunCommand(linuxCtx, "false", nil)
+	assert.Assert(t, commonError != nil)
+	assert.Assert(t, result.Exited(1))
+	assert.Equal(t, "failed\n", result.Stderr)
+
+	assert.DeepEqual(t, []string{"id -u", "false"}, server.Commands())
+}
+
+func TestRunCommandStdin(t *testing.T) {
+	server := NewServer(t).HandleFunc(func(command string, stdin string) (Response, bool) {
+		return Response{Stdout: stdin}, command == "cat"
+	})
+	linuxCtx := server.LinuxContext(t)
+
+	result, err := linuxCtx.ProviderData.Executor.RunWithStdin(linuxCtx.Ctx, "cat", strings.NewReader("input"))
+	assert.NilError(t, err)
+	assert.Equal(t, "input", result.Stdout)
+
+	result, err = linuxCtx.ProviderData.Executor.Run(linuxCtx.Ctx, "unknown")
+	assert.Assert(t, err != nil)
+	assert.Equal(t, 127, result.ExitCode)
+}
+
+func TestSftp(t *testing.T) {
+	server := NewServer(t)
+	linuxCtx := server.LinuxContext(t)
+	executor := linuxCtx.ProviderData.Executor
+
+	err := executor.Upload(linuxCtx.Ctx, strings.NewReader("content"), "/file")
+	assert.NilError(t, err)
+
+	var content bytes.Buffer
+	err = executor.Download(linuxCtx.Ctx, "/file", &content)
+	assert.NilError(t, err)
+	assert.Equal(t, "content", content.String())
+
+	local, err := os.ReadFile(server.Path("/file"))
+	assert.NilError(t, err)
+	assert.Equal(t, "content", string(local))
+
+	stat, err := executor.Stat(linuxCtx.Ctx, "/file")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(7), stat.Size)
+	assert.Equal(t, os.FileMode(0600), stat.Mode.Perm())
+
+	stat, err = executor.Stat(linuxCtx.Ctx, "/missing")
+	assert.NilError(t, err)
+	assert.Assert(t, stat == nil)
+}
@@ -9,6 +9,7 @@ import (
 	"log"
 	"terraform-provider-linux/internal/provider"
 
+	frameworkProvider "github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
 
@@ -25,7 +26,12 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// The provider is kept so that its connections are closed once Terraform stops the server.
+	linuxProvider := provider.New(version)().(*provider.LinuxProvider)
+	err := providerserver.Serve(context.Background(), func() frameworkProvider.Provider {
+		return linuxProvider
+	}, opts)
+	linuxProvider.Close()
 
 	if err != nil {
 		log.Fatal(err.Error())